@@ -0,0 +1,162 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrWriterClosed means that a write call is made after the writer is closed.
+var ErrWriterClosed = errors.New("writer closed")
+
+// NewAsyncWriter returns an AsyncWriter that queues the writes up to the given size and
+// drains them to the given writer on a background goroutine.
+// If the size is 0 then the default size (1 MiB) is used.
+func NewAsyncWriter(w io.Writer, size BinaryUnit) *AsyncWriter {
+	if size <= 0 {
+		size = MiB
+	}
+	aw := &AsyncWriter{w: w, size: int(size), done: make(chan struct{})}
+	aw.cond = sync.NewCond(&aw.mu)
+	go aw.drain()
+	return aw
+}
+
+// AsyncWriter implements the io.WriteCloser interface for writing asynchronously by a bounded queue.
+// The first error of the underlying writer is returned by the next Write, Flush or Close call.
+type AsyncWriter struct {
+	w        io.Writer
+	size     int
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    [][]byte
+	queued   int // queued is the number of bytes in the queue (including the one being written).
+	err      error
+	closed   bool
+	done     chan struct{}
+	enqueued *Progress
+	drained  *Progress
+}
+
+// SetProgress sets the progress writers for the enqueued and drained bytes. Either of them can be nil.
+func (aw *AsyncWriter) SetProgress(enqueued, drained *Progress) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	aw.enqueued = enqueued
+	aw.drained = drained
+}
+
+// Write implements the io.Writer interface.
+// It blocks while the queue is full and returns after the given bytes are enqueued.
+func (aw *AsyncWriter) Write(p []byte) (n int, err error) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	for len(p) > 0 {
+		// Split the large writes so they fit into the queue
+		c := len(p)
+		if c > aw.size {
+			c = aw.size
+		}
+
+		// Wait for space
+		for aw.err == nil && !aw.closed && aw.queued > 0 && aw.queued+c > aw.size {
+			aw.cond.Wait()
+		}
+		if aw.err != nil {
+			return n, aw.err
+		} else if aw.closed {
+			return n, ErrWriterClosed
+		}
+
+		// Enqueue
+		b := make([]byte, c)
+		copy(b, p)
+		if aw.enqueued != nil {
+			if _, err := aw.enqueued.Write(b); err != nil {
+				return n, err
+			}
+		}
+		aw.queue = append(aw.queue, b)
+		aw.queued += c
+		aw.cond.Broadcast()
+		n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// Buffered returns the number of bytes that are not written to the underlying writer yet.
+func (aw *AsyncWriter) Buffered() int {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	return aw.queued
+}
+
+// Flush waits until the queue is drained and returns the first error (if any).
+func (aw *AsyncWriter) Flush() error {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	for aw.err == nil && aw.queued > 0 {
+		aw.cond.Wait()
+	}
+	return aw.err
+}
+
+// Close drains the queue, stops the background goroutine and returns the first error (if any).
+// Note that it doesn't close the underlying writer.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	aw.closed = true
+	aw.cond.Broadcast()
+	aw.mu.Unlock()
+
+	<-aw.done
+
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	return aw.err
+}
+
+// drain writes the queue to the underlying writer until the writer is closed.
+func (aw *AsyncWriter) drain() {
+	defer close(aw.done)
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	for {
+		for len(aw.queue) == 0 && !aw.closed {
+			aw.cond.Wait()
+		}
+		if len(aw.queue) == 0 {
+			return
+		}
+		b := aw.queue[0]
+		aw.queue[0] = nil
+		aw.queue = aw.queue[1:]
+		drained := aw.drained
+
+		// Write without holding the lock
+		aw.mu.Unlock()
+		n, err := aw.w.Write(b)
+		if err == nil && n < len(b) {
+			err = io.ErrShortWrite
+		}
+		if drained != nil && n > 0 {
+			if _, perr := drained.Write(b[:n]); err == nil {
+				err = perr
+			}
+		}
+		aw.mu.Lock()
+
+		aw.queued -= len(b)
+		if err != nil && aw.err == nil {
+			// Drop the rest of the queue since it can't be written anymore.
+			aw.err = err
+			aw.queue = nil
+			aw.queued = 0
+		}
+		aw.cond.Broadcast()
+	}
+}
@@ -0,0 +1,104 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestAsyncWriter(t *testing.T) {
+	errFoo := errors.New("foo")
+	table := []struct {
+		writes []string
+		size   streamy.BinaryUnit
+		delay  time.Duration
+		failAt int
+		want   string
+		err    error
+	}{
+		{[]string{"foo", " bar", " baz"}, 0, 0, 0, "foo bar baz", nil},
+		{[]string{"foo", " bar", " baz"}, 4, time.Millisecond, 0, "foo bar baz", nil},
+		{[]string{"foo bar baz"}, 2, time.Millisecond, 0, "foo bar baz", nil},
+		{[]string{"foo", " bar", " baz"}, 4, time.Millisecond, 1, "foo", errFoo},
+	}
+	for _, v := range table {
+		sw := &slowWriter{delay: v.delay, failAt: v.failAt, err: errFoo}
+		aw := streamy.NewAsyncWriter(sw, v.size)
+		enqueued, drained := &streamy.Progress{}, &streamy.Progress{}
+		aw.SetProgress(enqueued, drained)
+
+		var err error
+		for _, s := range v.writes {
+			if _, err = io.WriteString(aw, s); err != nil {
+				break
+			}
+			// Give the background goroutine a chance to fail.
+			if v.failAt > 0 {
+				aw.Flush()
+			}
+		}
+		if err == nil {
+			err = aw.Flush()
+		}
+		if cerr := aw.Close(); cerr != v.err {
+			t.Errorf("got %v, want %v", cerr, v.err)
+		}
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if sw.String() != v.want {
+			t.Errorf("got %v, want %v", sw.String(), v.want)
+		} else if drained.BytesWritten() != int64(len(v.want)) {
+			t.Errorf("got %v, want %v", drained.BytesWritten(), len(v.want))
+		} else if v.err == nil && enqueued.BytesWritten() != int64(len(v.want)) {
+			t.Errorf("got %v, want %v", enqueued.BytesWritten(), len(v.want))
+		}
+		if _, err := aw.Write([]byte("foo")); err == nil {
+			t.Errorf("got nil, want error")
+		}
+	}
+}
+
+func BenchmarkAsyncWriter(b *testing.B) {
+	aw := streamy.NewAsyncWriter(io.Discard, streamy.KiB)
+	for i := 0; i < b.N; i++ {
+		aw.Write([]byte("foo bar baz"))
+	}
+	aw.Close()
+}
+
+// slowWriter implements a slow writer that fails at the given write call.
+type slowWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	delay  time.Duration
+	failAt int
+	err    error
+	writes int
+}
+
+// Write implements the io.Writer interface.
+func (sw *slowWriter) Write(p []byte) (n int, err error) {
+	time.Sleep(sw.delay)
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.writes++
+	if sw.failAt > 0 && sw.writes > sw.failAt {
+		return 0, sw.err
+	}
+	return sw.buf.Write(p)
+}
+
+// String returns the written bytes as a string.
+func (sw *slowWriter) String() string {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.buf.String()
+}
@@ -0,0 +1,134 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// NewBatchingWriter returns a BatchingWriter that coalesces the small writes to the given writer until
+// the given size is reached or the given max latency is passed since the first buffered write.
+// If the max latency is 0 then the buffered bytes are written only by the size threshold or Flush calls.
+func NewBatchingWriter(w io.Writer, size BinaryUnit, maxLatency time.Duration) *BatchingWriter {
+	return &BatchingWriter{w: w, size: int(size), maxLatency: maxLatency, clock: SystemClock}
+}
+
+// BatchingWriter implements the io.WriteCloser interface for coalescing small writes.
+// It's useful for reducing the number of write calls (i.e. Progress.Write) of chatty producers.
+type BatchingWriter struct {
+	mu         sync.Mutex
+	w          io.Writer
+	size       int
+	maxLatency time.Duration
+	clock      Clock
+	buf        []byte
+	timer      ClockTimer
+	gen        uint64 // gen is the batch generation for ignoring stale timers.
+	err        error
+	closed     bool
+}
+
+// SetClock sets the clock that is used for the max latency. The default clock is SystemClock.
+func (bw *BatchingWriter) SetClock(c Clock) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.clock = c
+}
+
+// Write implements the io.Writer interface.
+func (bw *BatchingWriter) Write(p []byte) (n int, err error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.err != nil {
+		return 0, bw.err
+	} else if bw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	// Flush the buffer first if the given bytes don't fit
+	if len(bw.buf) > 0 && len(bw.buf)+len(p) > bw.size {
+		if err := bw.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Write the large writes directly
+	if len(p) >= bw.size {
+		n, err = bw.w.Write(p)
+		if err != nil {
+			bw.err = err
+		}
+		return n, err
+	}
+
+	// Buffer the rest
+	if len(bw.buf) == 0 && bw.maxLatency > 0 {
+		gen := bw.gen
+		bw.timer = bw.clock.AfterFunc(bw.maxLatency, func() {
+			bw.mu.Lock()
+			defer bw.mu.Unlock()
+			if gen == bw.gen {
+				bw.flush()
+			}
+		})
+	}
+	bw.buf = append(bw.buf, p...)
+	if len(bw.buf) >= bw.size {
+		if err := bw.flush(); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Buffered returns the number of bytes that are not written to the underlying writer yet.
+func (bw *BatchingWriter) Buffered() int {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return len(bw.buf)
+}
+
+// Flush writes the buffered bytes to the underlying writer.
+func (bw *BatchingWriter) Flush() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.err != nil {
+		return bw.err
+	}
+	return bw.flush()
+}
+
+// Close flushes the buffered bytes. Note that it doesn't close the underlying writer.
+func (bw *BatchingWriter) Close() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return bw.err
+	}
+	bw.closed = true
+	if bw.err != nil {
+		return bw.err
+	}
+	return bw.flush()
+}
+
+// flush writes the buffered bytes and starts a new batch. The caller must hold the lock.
+func (bw *BatchingWriter) flush() error {
+	if bw.timer != nil {
+		bw.timer.Stop()
+		bw.timer = nil
+	}
+	bw.gen++
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	_, err := bw.w.Write(bw.buf)
+	bw.buf = bw.buf[:0]
+	if err != nil {
+		bw.err = err
+	}
+	return err
+}
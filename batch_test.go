@@ -0,0 +1,92 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestBatchingWriter(t *testing.T) {
+	latency := 100 * time.Millisecond
+	table := []struct {
+		steps  []func(bw *streamy.BatchingWriter, fc *fakeClock)
+		size   streamy.BinaryUnit
+		blocks []string
+	}{
+		{
+			steps: []func(bw *streamy.BatchingWriter, fc *fakeClock){
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "fo") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "o ") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "ba") },
+			},
+			size:   4,
+			blocks: []string{"foo ", "ba"},
+		},
+		{
+			steps: []func(bw *streamy.BatchingWriter, fc *fakeClock){
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "foo") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, " bar baz") },
+			},
+			size:   4,
+			blocks: []string{"foo", " bar baz"},
+		},
+		{
+			steps: []func(bw *streamy.BatchingWriter, fc *fakeClock){
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "foo") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { fc.Advance(latency / 2) },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, " bar") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { fc.Advance(latency / 2) },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, " baz") },
+			},
+			size:   streamy.KiB,
+			blocks: []string{"foo bar", " baz"},
+		},
+		{
+			steps: []func(bw *streamy.BatchingWriter, fc *fakeClock){
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, "foo") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { bw.Flush() },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { fc.Advance(latency / 2) },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, " bar") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { fc.Advance(latency / 2) },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { io.WriteString(bw, " baz") },
+				func(bw *streamy.BatchingWriter, fc *fakeClock) { fc.Advance(latency / 2) },
+			},
+			size:   streamy.KiB,
+			blocks: []string{"foo", " bar baz"},
+		},
+	}
+	for _, v := range table {
+		rec := &blockRecorder{}
+		fc := newFakeClock()
+		bw := streamy.NewBatchingWriter(rec, v.size, latency)
+		bw.SetClock(fc)
+		for _, step := range v.steps {
+			step(bw, fc)
+		}
+		if err := bw.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if len(rec.blocks) != len(v.blocks) {
+			t.Errorf("got %q, want %q", rec.blocks, v.blocks)
+			continue
+		}
+		for i, b := range rec.blocks {
+			if b != v.blocks[i] {
+				t.Errorf("got %q, want %q", b, v.blocks[i])
+			}
+		}
+	}
+}
+
+func BenchmarkBatchingWriter(b *testing.B) {
+	bw := streamy.NewBatchingWriter(io.Discard, streamy.KiB, time.Second)
+	for i := 0; i < b.N; i++ {
+		bw.Write([]byte("foo bar baz"))
+	}
+	bw.Close()
+}
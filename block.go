@@ -0,0 +1,193 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrShortBlock means that the final block is shorter than the block size.
+var ErrShortBlock = errors.New("short block")
+
+// NewBlockWriter returns a BlockWriter that writes exactly the given size of blocks to the given writer.
+// The given policy is applied to the final short block when the writer is closed.
+func NewBlockWriter(w io.Writer, size BinaryUnit, policy BlockPolicy) (*BlockWriter, error) {
+	if size <= 0 {
+		return nil, errors.New("invalid block size")
+	}
+	return &BlockWriter{w: w, size: int(size), policy: policy}, nil
+}
+
+// BlockWriter implements the io.WriteCloser interface for writing fixed size blocks.
+type BlockWriter struct {
+	w      io.Writer
+	size   int
+	policy BlockPolicy
+	buf    []byte
+	closed bool
+}
+
+// Write implements the io.Writer interface.
+func (bw *BlockWriter) Write(p []byte) (n int, err error) {
+	if bw.closed {
+		return 0, errors.New("write to closed block writer")
+	}
+	for len(p) > 0 {
+		// Write the full blocks directly if there is nothing buffered
+		if len(bw.buf) == 0 && len(p) >= bw.size {
+			written, err := bw.w.Write(p[:bw.size])
+			n += written
+			if err != nil {
+				return n, err
+			}
+			p = p[bw.size:]
+			continue
+		}
+
+		// Buffer the rest
+		if bw.buf == nil {
+			bw.buf = make([]byte, 0, bw.size)
+		}
+		c := bw.size - len(bw.buf)
+		if c > len(p) {
+			c = len(p)
+		}
+		bw.buf = append(bw.buf, p[:c]...)
+		n += c
+		p = p[c:]
+		if len(bw.buf) == bw.size {
+			if _, err := bw.w.Write(bw.buf); err != nil {
+				return n, err
+			}
+			bw.buf = bw.buf[:0]
+		}
+	}
+	return n, nil
+}
+
+// Buffered returns the number of bytes that are waiting for a full block.
+func (bw *BlockWriter) Buffered() int {
+	return len(bw.buf)
+}
+
+// Close writes the final short block (if any) by the block policy.
+// Note that it doesn't close the underlying writer.
+func (bw *BlockWriter) Close() error {
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	switch bw.policy {
+	case BlockPolicyPad:
+		bw.buf = bw.buf[:bw.size]
+		for i := len(bw.buf); i < bw.size; i++ {
+			bw.buf[i] = 0
+		}
+	case BlockPolicyError:
+		return ErrShortBlock
+	}
+	_, err := bw.w.Write(bw.buf)
+	return err
+}
+
+// NewBlockReader returns a BlockReader that reads exactly the given size of blocks from the given reader.
+// The given policy is applied to the final short block.
+func NewBlockReader(r io.Reader, size BinaryUnit, policy BlockPolicy) (*BlockReader, error) {
+	if size <= 0 {
+		return nil, errors.New("invalid block size")
+	}
+	return &BlockReader{r: r, size: int(size), policy: policy}, nil
+}
+
+// BlockReader implements the io.Reader interface for reading fixed size blocks.
+// Each read call returns at most one block so the reads don't span blocks. A block is served by
+// multiple read calls when the given byte slice is shorter than the block size (see ReadBlock).
+type BlockReader struct {
+	r      io.Reader
+	size   int
+	policy BlockPolicy
+	buf    []byte
+	rest   []byte // rest is the unread part of the current block.
+	err    error
+}
+
+// Read implements the io.Reader interface.
+func (br *BlockReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(br.rest) == 0 {
+		b, err := br.readBlock()
+		if len(b) == 0 {
+			return 0, err
+		}
+		br.rest = b
+	}
+	n = copy(p, br.rest)
+	br.rest = br.rest[n:]
+	return n, nil
+}
+
+// ReadBlock reads the next block. It returns io.EOF when there are no more blocks.
+// If the block is partially read by the Read method then the rest of it is returned.
+func (br *BlockReader) ReadBlock() ([]byte, error) {
+	if len(br.rest) > 0 {
+		b := append([]byte(nil), br.rest...)
+		br.rest = nil
+		return b, nil
+	}
+	b, err := br.readBlock()
+	if len(b) == 0 {
+		return nil, err
+	}
+	return append([]byte(nil), b...), err
+}
+
+// readBlock reads the next block into the buffer and applies the policy to the final short block.
+// Note that the returned byte slice is only valid until the next call.
+func (br *BlockReader) readBlock() ([]byte, error) {
+	if br.err != nil {
+		return nil, br.err
+	}
+	if br.buf == nil {
+		br.buf = make([]byte, br.size)
+	}
+	n, err := io.ReadFull(br.r, br.buf)
+	if err == io.ErrUnexpectedEOF {
+		// Apply the policy to the final short block
+		br.err = io.EOF
+		switch br.policy {
+		case BlockPolicyPad:
+			for i := n; i < br.size; i++ {
+				br.buf[i] = 0
+			}
+			return br.buf, nil
+		case BlockPolicyError:
+			br.err = ErrShortBlock
+			return nil, ErrShortBlock
+		}
+		return br.buf[:n], nil
+	} else if err != nil {
+		br.err = err
+	}
+	return br.buf[:n], err
+}
+
+// BlockPolicy represents a policy for the final short block.
+type BlockPolicy struct {
+	policy uint8
+}
+
+var (
+	// BlockPolicyFlush represents the policy that keeps the final short block as it is.
+	BlockPolicyFlush = BlockPolicy{policy: 0}
+	// BlockPolicyPad represents the policy that pads the final short block with zeros.
+	BlockPolicyPad = BlockPolicy{policy: 1}
+	// BlockPolicyError represents the policy that returns ErrShortBlock for the final short block.
+	BlockPolicyError = BlockPolicy{policy: 2}
+)
@@ -0,0 +1,160 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestBlockWriter(t *testing.T) {
+	table := []struct {
+		writes []string
+		size   streamy.BinaryUnit
+		policy streamy.BlockPolicy
+		blocks []string
+		err    error
+	}{
+		{[]string{"foo bar baz"}, 4, streamy.BlockPolicyFlush, []string{"foo ", "bar ", "baz"}, nil},
+		{[]string{"f", "oo b", "ar", " baz"}, 4, streamy.BlockPolicyFlush, []string{"foo ", "bar ", "baz"}, nil},
+		{[]string{"foo bar baz"}, 4, streamy.BlockPolicyPad, []string{"foo ", "bar ", "baz\x00"}, nil},
+		{[]string{"foo bar baz"}, 4, streamy.BlockPolicyError, []string{"foo ", "bar "}, streamy.ErrShortBlock},
+		{[]string{"foo bar "}, 4, streamy.BlockPolicyError, []string{"foo ", "bar "}, nil},
+		{[]string{}, 4, streamy.BlockPolicyPad, nil, nil},
+	}
+	for _, v := range table {
+		bw := &blockRecorder{}
+		w, err := streamy.NewBlockWriter(bw, v.size, v.policy)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		for _, s := range v.writes {
+			if n, err := io.WriteString(w, s); err != nil {
+				t.Errorf("got %v, want nil", err)
+			} else if n != len(s) {
+				t.Errorf("got %v, want %v", n, len(s))
+			}
+		}
+		if err := w.Close(); err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+		if len(bw.blocks) != len(v.blocks) {
+			t.Errorf("got %q, want %q", bw.blocks, v.blocks)
+			continue
+		}
+		for i, b := range bw.blocks {
+			if b != v.blocks[i] {
+				t.Errorf("got %q, want %q", b, v.blocks[i])
+			}
+		}
+	}
+}
+
+func TestBlockReader(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		size   streamy.BinaryUnit
+		policy streamy.BlockPolicy
+		blocks []string
+		err    error
+	}{
+		{bytes.NewBufferString("foo bar baz"), 4, streamy.BlockPolicyFlush, []string{"foo ", "bar ", "baz"}, io.EOF},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 4, streamy.BlockPolicyFlush, []string{"foo ", "bar ", "baz"}, io.EOF},
+		{bytes.NewBufferString("foo bar baz"), 4, streamy.BlockPolicyPad, []string{"foo ", "bar ", "baz\x00"}, io.EOF},
+		{bytes.NewBufferString("foo bar baz"), 4, streamy.BlockPolicyError, []string{"foo ", "bar "}, streamy.ErrShortBlock},
+		{bytes.NewBufferString("foo bar "), 4, streamy.BlockPolicyError, []string{"foo ", "bar "}, io.EOF},
+	}
+	for _, v := range table {
+		br, err := streamy.NewBlockReader(v.reader, v.size, v.policy)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		var blocks []string
+		for {
+			var n int
+			p := make([]byte, 8)
+			n, err = br.Read(p)
+			if n > 0 {
+				blocks = append(blocks, string(p[:n]))
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+		if len(blocks) != len(v.blocks) {
+			t.Errorf("got %q, want %q", blocks, v.blocks)
+			continue
+		}
+		for i, b := range blocks {
+			if b != v.blocks[i] {
+				t.Errorf("got %q, want %q", b, v.blocks[i])
+			}
+		}
+	}
+
+	// Short byte slices are served from the current block
+	br, _ := streamy.NewBlockReader(bytes.NewBufferString("foo bar baz"), 4, streamy.BlockPolicyPad)
+	if b, err := io.ReadAll(iotest.OneByteReader(br)); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if string(b) != "foo bar baz\x00" {
+		t.Errorf("got %q, want %q", b, "foo bar baz\x00")
+	}
+
+	// Full blocks are returned by ReadBlock even after a partial read
+	br, _ = streamy.NewBlockReader(bytes.NewBufferString("foo bar baz"), 4, streamy.BlockPolicyFlush)
+	br.Read(make([]byte, 1))
+	var blocks []string
+	for {
+		b, err := br.ReadBlock()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		blocks = append(blocks, string(b))
+	}
+	if want := []string{"oo ", "bar ", "baz"}; strings.Join(blocks, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", blocks, want)
+	}
+	br, _ = streamy.NewBlockReader(bytes.NewBufferString("foo"), 4, streamy.BlockPolicyError)
+	if _, err := br.ReadBlock(); err != streamy.ErrShortBlock {
+		t.Errorf("got %v, want %v", err, streamy.ErrShortBlock)
+	}
+
+	for _, size := range []streamy.BinaryUnit{0, -1} {
+		if _, err := streamy.NewBlockReader(bytes.NewBufferString("foo"), size, streamy.BlockPolicyFlush); err == nil {
+			t.Errorf("got nil, want error")
+		}
+		if _, err := streamy.NewBlockWriter(io.Discard, size, streamy.BlockPolicyFlush); err == nil {
+			t.Errorf("got nil, want error")
+		}
+	}
+}
+
+func BenchmarkBlockWriter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		w, _ := streamy.NewBlockWriter(io.Discard, 4, streamy.BlockPolicyPad)
+		io.WriteString(w, "foo bar baz")
+		w.Close()
+	}
+}
+
+// blockRecorder implements a writer that records each write call.
+type blockRecorder struct {
+	blocks []string
+}
+
+// Write implements the io.Writer interface.
+func (br *blockRecorder) Write(p []byte) (n int, err error) {
+	br.blocks = append(br.blocks, string(p))
+	return len(p), nil
+}
@@ -0,0 +1,198 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrSlowConsumer means that a consumer is dropped since it couldn't keep up with the others.
+var ErrSlowConsumer = errors.New("slow consumer")
+
+// NewBroadcaster returns a Broadcaster that broadcasts the given reader to the given number of consumers.
+// Each consumer buffers up to the given size of bytes and the given policy is applied when a buffer is full.
+// If the size is 0 then the default size (1 MiB) is used.
+func NewBroadcaster(r io.Reader, n int, size BinaryUnit, policy BroadcastPolicy) *Broadcaster {
+	if size <= 0 {
+		size = MiB
+	}
+	b := &Broadcaster{r: r, size: int(size), policy: policy}
+	b.cond = sync.NewCond(&b.mu)
+	for i := 0; i < n; i++ {
+		b.consumers = append(b.consumers, &broadcastConsumer{b: b})
+	}
+	go b.pump()
+	return b
+}
+
+// Broadcaster represents a broadcaster that turns one reader into multiple independent readers.
+// The consumers should be read in separate goroutines. An error of a consumer (i.e. closed or dropped)
+// doesn't affect the others.
+type Broadcaster struct {
+	r         io.Reader
+	size      int
+	policy    BroadcastPolicy
+	mu        sync.Mutex
+	cond      *sync.Cond
+	consumers []*broadcastConsumer
+}
+
+// Readers returns the consumer readers.
+func (b *Broadcaster) Readers() []io.ReadCloser {
+	readers := make([]io.ReadCloser, len(b.consumers))
+	for i, c := range b.consumers {
+		readers[i] = c
+	}
+	return readers
+}
+
+// pump reads from the underlying reader and pushes the bytes to the consumers until an error occurs.
+func (b *Broadcaster) pump() {
+	chunk := 32 * 1024
+	if chunk > b.size {
+		chunk = b.size
+	}
+	buf := make([]byte, chunk)
+	for {
+		n, err := b.r.Read(buf)
+		b.mu.Lock()
+		if n > 0 {
+			// The bytes are shared by the consumers so they are copied to a slice of the exact size.
+			// Otherwise the short reads would keep the entire chunk and exceed the buffer size.
+			p := make([]byte, n)
+			copy(p, buf[:n])
+			b.push(p)
+		}
+		active := 0
+		for _, c := range b.consumers {
+			if err != nil && c.err == nil {
+				c.err = err
+			}
+			if c.active() {
+				active++
+			}
+		}
+		b.cond.Broadcast()
+		b.mu.Unlock()
+
+		// Stop if there is no one to read
+		if err != nil || active == 0 {
+			return
+		}
+	}
+}
+
+// push pushes the given bytes to the consumers by the policy. The caller must hold the lock.
+func (b *Broadcaster) push(p []byte) {
+	if b.policy == BroadcastPolicyDrop {
+		// Wait until the fastest consumer has space and then drop the ones that are still full.
+		for !b.anyAccepts(len(p)) {
+			b.cond.Wait()
+		}
+		for _, c := range b.consumers {
+			if c.active() && !c.accepts(len(p)) {
+				c.err = ErrSlowConsumer
+			}
+		}
+	}
+	for _, c := range b.consumers {
+		for c.active() && !c.accepts(len(p)) {
+			b.cond.Wait()
+		}
+		if c.active() {
+			c.queue = append(c.queue, p)
+			c.queued += len(p)
+		}
+	}
+	b.cond.Broadcast()
+}
+
+// anyAccepts checks whether any active consumer (or none) accepts the given number of bytes.
+func (b *Broadcaster) anyAccepts(n int) bool {
+	active := false
+	for _, c := range b.consumers {
+		if c.active() {
+			if c.accepts(n) {
+				return true
+			}
+			active = true
+		}
+	}
+	return !active
+}
+
+// broadcastConsumer represents a consumer of a Broadcaster.
+type broadcastConsumer struct {
+	b      *Broadcaster
+	queue  [][]byte
+	queued int
+	err    error // err is returned after the queue is drained.
+	closed bool
+}
+
+// active checks whether the consumer still receives bytes or not.
+func (c *broadcastConsumer) active() bool {
+	return !c.closed && c.err == nil
+}
+
+// accepts checks whether the consumer has space for the given number of bytes or not.
+// Note that an empty consumer always accepts so the chunks larger than the buffer size don't block.
+func (c *broadcastConsumer) accepts(n int) bool {
+	return c.queued == 0 || c.queued+n <= c.b.size
+}
+
+// Read implements the io.Reader interface.
+func (c *broadcastConsumer) Read(p []byte) (n int, err error) {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+	for len(c.queue) == 0 && c.err == nil && !c.closed {
+		c.b.cond.Wait()
+	}
+	if c.closed {
+		return 0, os.ErrClosed
+	} else if len(c.queue) == 0 {
+		return 0, c.err
+	}
+	for len(c.queue) > 0 && n < len(p) {
+		m := copy(p[n:], c.queue[0])
+		if m == len(c.queue[0]) {
+			c.queue[0] = nil
+			c.queue = c.queue[1:]
+		} else {
+			c.queue[0] = c.queue[0][m:]
+		}
+		n += m
+	}
+	c.queued -= n
+	c.b.cond.Broadcast()
+	return n, nil
+}
+
+// Close implements the io.Closer interface. It detaches the consumer from the broadcaster.
+func (c *broadcastConsumer) Close() error {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+	c.closed = true
+	c.queue = nil
+	c.queued = 0
+	c.b.cond.Broadcast()
+	return nil
+}
+
+// BroadcastPolicy represents a policy for the consumers whose buffers are full.
+type BroadcastPolicy struct {
+	policy uint8
+}
+
+var (
+	// BroadcastPolicyBlock represents the policy that blocks the broadcaster until the slowest consumer
+	// has space (i.e. backpressure).
+	BroadcastPolicyBlock = BroadcastPolicy{policy: 0}
+	// BroadcastPolicyDrop represents the policy that drops the consumers which are still full when
+	// the fastest consumer has space. The dropped consumers return ErrSlowConsumer.
+	BroadcastPolicyDrop = BroadcastPolicy{policy: 1}
+)
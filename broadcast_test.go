@@ -0,0 +1,169 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestBroadcaster(t *testing.T) {
+	errFoo := errors.New("foo")
+	content := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	table := []struct {
+		reader io.Reader
+		n      int
+		size   streamy.BinaryUnit
+		want   []byte
+		err    error
+	}{
+		{bytes.NewReader(content), 3, 0, content, nil},
+		{bytes.NewReader(content), 2, 100, content, nil},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 4, 1, []byte("foo bar baz"), nil},
+		{io.MultiReader(bytes.NewBufferString("foo bar baz"), iotest.ErrReader(errFoo)), 2, 4, []byte("foo bar baz"), errFoo},
+	}
+	for _, v := range table {
+		b := streamy.NewBroadcaster(v.reader, v.n, v.size, streamy.BroadcastPolicyBlock)
+		readers := b.Readers()
+		if len(readers) != v.n {
+			t.Errorf("got %v, want %v", len(readers), v.n)
+			continue
+		}
+		var wg sync.WaitGroup
+		results := make([][]byte, v.n)
+		errs := make([]error, v.n)
+		for i, r := range readers {
+			wg.Add(1)
+			go func(i int, r io.Reader) {
+				defer wg.Done()
+				results[i], errs[i] = io.ReadAll(r)
+			}(i, r)
+		}
+		wg.Wait()
+		for i := range readers {
+			if errs[i] != v.err {
+				t.Errorf("got %v, want %v", errs[i], v.err)
+			} else if sha256.Sum256(results[i]) != sha256.Sum256(v.want) {
+				t.Errorf("got %v bytes, want %v bytes", len(results[i]), len(v.want))
+			}
+		}
+	}
+}
+
+func TestBroadcasterIsolation(t *testing.T) {
+	table := []struct {
+		policy streamy.BroadcastPolicy
+		close  bool
+		err    error
+	}{
+		{streamy.BroadcastPolicyBlock, true, os.ErrClosed},
+		{streamy.BroadcastPolicyDrop, false, streamy.ErrSlowConsumer},
+	}
+	for _, v := range table {
+		content := bytes.Repeat([]byte("foo bar baz "), 100)
+		b := streamy.NewBroadcaster(bytes.NewReader(content), 2, 16, v.policy)
+		readers := b.Readers()
+
+		// The first consumer reads everything while the second one is stuck.
+		done := make(chan struct{})
+		var got []byte
+		var err error
+		go func() {
+			defer close(done)
+			got, err = io.ReadAll(iotest.OneByteReader(readers[0]))
+		}()
+		if v.close {
+			time.Sleep(10 * time.Millisecond)
+			readers[1].Close()
+		}
+		<-done
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(got, content) {
+			t.Errorf("got %v bytes, want %v bytes", len(got), len(content))
+		}
+		_, err = io.ReadAll(readers[1])
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+	}
+}
+
+func TestBroadcasterMemory(t *testing.T) {
+	// The consumer never reads so the broadcaster should stop pulling from the source at the buffer size
+	// (plus the chunk that is waiting for space) even if the reads are short.
+	size := 4 * streamy.KiB
+	table := []struct {
+		reader func(r io.Reader) io.Reader
+		chunk  int64
+	}{
+		{iotest.OneByteReader, 1},
+		{func(r io.Reader) io.Reader { return r }, int64(size)},
+	}
+	for _, v := range table {
+		cr := &countingReader{r: v.reader(bytes.NewReader(make([]byte, 1<<20)))}
+		b := streamy.NewBroadcaster(cr, 1, size, streamy.BroadcastPolicyBlock)
+
+		// Wait until the broadcaster blocks
+		var read int64
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+			if n := cr.count(); n > 0 && n == read {
+				break
+			} else {
+				read = n
+			}
+		}
+		if read < int64(size) || read > int64(size)+v.chunk {
+			t.Errorf("got %v bytes, want %v-%v bytes", read, size, int64(size)+v.chunk)
+		}
+		b.Readers()[0].Close()
+	}
+}
+
+func BenchmarkBroadcaster(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		bc := streamy.NewBroadcaster(bytes.NewBufferString("foo bar baz"), 2, streamy.KiB, streamy.BroadcastPolicyBlock)
+		var wg sync.WaitGroup
+		for _, r := range bc.Readers() {
+			wg.Add(1)
+			go func(r io.Reader) {
+				defer wg.Done()
+				io.Copy(io.Discard, r)
+			}(r)
+		}
+		wg.Wait()
+	}
+}
+
+// countingReader implements a reader that counts the bytes read from the given reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements the io.Reader interface.
+func (cr *countingReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	atomic.AddInt64(&cr.n, int64(n))
+	return n, err
+}
+
+// count returns the number of bytes read so far.
+func (cr *countingReader) count() int64 {
+	return atomic.LoadInt64(&cr.n)
+}
@@ -0,0 +1,151 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+)
+
+// NewChunker returns a Chunker that splits the given reader into content-defined chunks
+// by the given minimum, average and maximum chunk sizes.
+func NewChunker(r io.Reader, minSize, avgSize, maxSize BinaryUnit) (*Chunker, error) {
+	if minSize <= 0 || minSize > avgSize || avgSize > maxSize {
+		return nil, errors.New("invalid chunk sizes")
+	}
+	// Use one more bit for the small mask and one less bit for the large mask (normalized chunking).
+	// Ref: https://www.usenix.org/system/files/conference/atc16/atc16-paper-xia.pdf
+	n := bits.Len64(uint64(avgSize)) - 1
+	if n < 2 {
+		n = 2
+	}
+	return &Chunker{
+		r:     r,
+		min:   int(minSize),
+		avg:   int(avgSize),
+		max:   int(maxSize),
+		maskS: chunkerMask(n + 1),
+		maskL: chunkerMask(n - 1),
+		buf:   make([]byte, 2*int(maxSize)),
+	}, nil
+}
+
+// Chunker implements content-defined chunking (FastCDC) by using a gear based rolling hash.
+// Since the boundaries depend on the content, insertions and deletions only shift the nearby boundaries.
+type Chunker struct {
+	r      io.Reader
+	min    int
+	avg    int
+	max    int
+	maskS  uint64
+	maskL  uint64
+	buf    []byte
+	start  int
+	end    int
+	offset int64
+	err    error
+	digest func() hash.Hash
+}
+
+// SetDigest sets the hash function that is used for computing the chunk digests.
+func (c *Chunker) SetDigest(f func() hash.Hash) {
+	c.digest = f
+}
+
+// Next returns the next chunk. It returns io.EOF when there are no more chunks.
+// Note that the chunk data is only valid until the next call.
+func (c *Chunker) Next() (Chunk, error) {
+	// Fill the buffer so there is at least one maximum size chunk (or the rest of the stream)
+	if c.end-c.start < c.max && c.err == nil {
+		c.end = copy(c.buf, c.buf[c.start:c.end])
+		c.start = 0
+		for c.end < len(c.buf) && c.err == nil {
+			var n int
+			n, c.err = c.r.Read(c.buf[c.end:])
+			c.end += n
+		}
+	}
+	if c.start == c.end {
+		if c.err == nil || c.err == io.EOF {
+			return Chunk{}, io.EOF
+		}
+		return Chunk{}, c.err
+	}
+
+	// Cut the chunk
+	data := c.buf[c.start:c.end]
+	data = data[:c.cut(data)]
+	chunk := Chunk{
+		Offset: c.offset,
+		Length: len(data),
+		Data:   data,
+	}
+	if c.digest != nil {
+		h := c.digest()
+		h.Write(data)
+		chunk.Digest = h.Sum(nil)
+	}
+	c.start += len(data)
+	c.offset += int64(len(data))
+	return chunk, nil
+}
+
+// cut returns the length of the chunk at the beginning of the given bytes.
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.min {
+		return n
+	} else if n > c.max {
+		n = c.max
+	}
+	normal := c.avg
+	if normal > n {
+		normal = n
+	}
+	var fp uint64
+	i := c.min
+	for ; i < normal; i++ {
+		fp = (fp << 1) + chunkerGear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + chunkerGear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// Chunk represents a content-defined chunk.
+type Chunk struct {
+	Offset int64
+	Length int
+	Data   []byte
+	Digest []byte // Digest is nil unless a digest is set (see Chunker.SetDigest).
+}
+
+// chunkerMask returns a mask with the given number of high bits set.
+// The high bits are used since they are affected by the most bytes in the rolling window.
+func chunkerMask(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+// chunkerGear is the gear table of the rolling hash.
+var chunkerGear = func() (gear [256]uint64) {
+	// Generate the table by using splitmix64 so the boundaries are stable.
+	seed := uint64(0x5ca1ab1e)
+	for i := range gear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+	return gear
+}()
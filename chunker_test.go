@@ -0,0 +1,117 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestChunker(t *testing.T) {
+	content := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	table := []struct {
+		reader  io.Reader
+		content []byte
+		min     streamy.BinaryUnit
+		avg     streamy.BinaryUnit
+		max     streamy.BinaryUnit
+		err     bool
+	}{
+		{bytes.NewReader(content), content, 2 * streamy.KiB, 8 * streamy.KiB, 32 * streamy.KiB, false},
+		{iotest.HalfReader(bytes.NewReader(content)), content, 2 * streamy.KiB, 8 * streamy.KiB, 32 * streamy.KiB, false},
+		{bytes.NewReader(content[:100]), content[:100], 2 * streamy.KiB, 8 * streamy.KiB, 32 * streamy.KiB, false},
+		{bytes.NewReader(nil), nil, 2 * streamy.KiB, 8 * streamy.KiB, 32 * streamy.KiB, false},
+		{bytes.NewReader(content), content, 8 * streamy.KiB, 2 * streamy.KiB, 32 * streamy.KiB, true},
+		{bytes.NewReader(content), content, 0, 0, 0, true},
+	}
+	for _, v := range table {
+		chunker, err := streamy.NewChunker(v.reader, v.min, v.avg, v.max)
+		if v.err {
+			if err == nil {
+				t.Errorf("got nil, want error")
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("got %v, want nil", err)
+			continue
+		}
+		var buf bytes.Buffer
+		for {
+			chunk, err := chunker.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("got %v, want nil", err)
+				break
+			}
+			if chunk.Offset != int64(buf.Len()) {
+				t.Errorf("got %v, want %v", chunk.Offset, buf.Len())
+			} else if chunk.Length > int(v.max) {
+				t.Errorf("got %v, want <=%v", chunk.Length, v.max)
+			} else if chunk.Length < int(v.min) && chunk.Offset+int64(chunk.Length) != int64(len(v.content)) {
+				t.Errorf("got %v, want >=%v", chunk.Length, v.min)
+			}
+			buf.Write(chunk.Data)
+		}
+		if !bytes.Equal(buf.Bytes(), v.content) {
+			t.Errorf("got %v bytes, want %v bytes", buf.Len(), len(v.content))
+		}
+	}
+}
+
+func TestChunkerShift(t *testing.T) {
+	content := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+	shifted := append([]byte("foo"), content...)
+
+	digests := func(b []byte) map[[sha256.Size]byte]bool {
+		m := map[[sha256.Size]byte]bool{}
+		chunker, _ := streamy.NewChunker(bytes.NewReader(b), 2*streamy.KiB, 8*streamy.KiB, 32*streamy.KiB)
+		chunker.SetDigest(sha256.New)
+		for {
+			chunk, err := chunker.Next()
+			if err != nil {
+				break
+			}
+			var d [sha256.Size]byte
+			copy(d[:], chunk.Digest)
+			m[d] = true
+		}
+		return m
+	}
+	a, b := digests(content), digests(shifted)
+	missing := 0
+	for d := range a {
+		if !b[d] {
+			missing++
+		}
+	}
+	// Only the chunks around the insertion should change.
+	if missing > 2 {
+		t.Errorf("got %v changed chunks of %v, want <=2", missing, len(a))
+	}
+}
+
+func BenchmarkChunker(b *testing.B) {
+	content := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		chunker, _ := streamy.NewChunker(bytes.NewReader(content), 2*streamy.KiB, 8*streamy.KiB, 32*streamy.KiB)
+		for {
+			if _, err := chunker.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"time"
+)
+
+// SystemClock is the Clock that uses the system time.
+var SystemClock Clock = systemClock{}
+
+// Clock represents a clock for telling the time and scheduling functions.
+// It's useful for injecting a fake clock into time dependent writers for deterministic tests.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer represents a timer that is created by a Clock.
+type ClockTimer interface {
+	Stop() bool
+}
+
+// systemClock implements the Clock interface by using the time package.
+type systemClock struct{}
+
+// Now implements the Clock interface.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc implements the Clock interface.
+func (systemClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
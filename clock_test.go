@@ -0,0 +1,112 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestSystemClock(t *testing.T) {
+	delay := 10 * time.Millisecond
+	table := []struct {
+		stop bool
+		want bool
+	}{
+		{false, true},
+		{true, false},
+	}
+	for _, v := range table {
+		ch := make(chan struct{}, 1)
+		start := streamy.SystemClock.Now()
+		timer := streamy.SystemClock.AfterFunc(delay, func() { ch <- struct{}{} })
+		if v.stop {
+			timer.Stop()
+		}
+		select {
+		case <-ch:
+			if !v.want {
+				t.Errorf("got fired, want not fired")
+			} else if d := time.Since(start); d < delay {
+				t.Errorf("got %v, want >=%v", d, delay)
+			}
+		case <-time.After(delay * 5):
+			if v.want {
+				t.Errorf("got not fired, want fired")
+			}
+		}
+	}
+}
+
+// fakeClock implements a fake clock that moves only by calling the Advance method.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// newFakeClock returns a new fake clock.
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now implements the streamy.Clock interface.
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// AfterFunc implements the streamy.Clock interface.
+func (fc *fakeClock) AfterFunc(d time.Duration, f func()) streamy.ClockTimer {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	ft := &fakeTimer{clock: fc, at: fc.now.Add(d), f: f}
+	fc.timers = append(fc.timers, ft)
+	return ft
+}
+
+// Advance moves the clock by the given duration and runs the due timers.
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	fc.now = fc.now.Add(d)
+	var due []*fakeTimer
+	timers := fc.timers[:0]
+	for _, ft := range fc.timers {
+		if !ft.at.After(fc.now) {
+			due = append(due, ft)
+		} else {
+			timers = append(timers, ft)
+		}
+	}
+	fc.timers = timers
+	fc.mu.Unlock()
+
+	for _, ft := range due {
+		ft.f()
+	}
+}
+
+// fakeTimer implements a timer of the fake clock.
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+}
+
+// Stop implements the streamy.ClockTimer interface.
+func (ft *fakeTimer) Stop() bool {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+	for i, v := range ft.clock.timers {
+		if v == ft {
+			ft.clock.timers = append(ft.clock.timers[:i], ft.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
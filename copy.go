@@ -0,0 +1,193 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"context"
+	"io"
+)
+
+// CopyOptions represents the options for the copy functions.
+type CopyOptions struct {
+	// SegmentSize is the number of bytes copied by each fast path call (i.e. io.ReaderFrom) between
+	// the progress updates. If it's 0 then the default size (1 MiB) is used.
+	SegmentSize BinaryUnit
+	// BufferSize is the size of the copy buffer. If it's 0 then the default size (32 KiB) is used.
+	BufferSize BinaryUnit
+	// BufferCount is the number of the buffers used by ParallelCopy. If it's less than 2 then
+	// the default count (2) is used.
+	BufferCount int
+}
+
+// CopyWithProgress copies from the given reader to the given writer until EOF or an error occurs and
+// updates the given progress (if any) as the bytes are copied. Unlike io.Copy with an io.MultiWriter,
+// it keeps the fast paths (i.e. copy_file_range, sendfile and splice for *os.File) by copying bounded
+// segments to a writer that implements io.ReaderFrom. Otherwise it uses a buffered loop (io.WriterTo of
+// the reader isn't used since it can't be bounded by segments). The copy stops when the given context is
+// done (checked between segments and buffers) or the progress is stopped by its controls or limits.
+// It returns the number of bytes written and the first error (if any) except io.EOF.
+func CopyWithProgress(ctx context.Context, dst io.Writer, src io.Reader, progress *Progress, opts *CopyOptions) (written int64, err error) {
+	segment, size := int64(MiB), 32*KiB
+	if opts != nil {
+		if opts.SegmentSize > 0 {
+			segment = int64(opts.SegmentSize)
+		}
+		if opts.BufferSize > 0 {
+			size = opts.BufferSize
+		}
+	}
+
+	// Copy the segments by io.ReaderFrom
+	if rf, ok := dst.(io.ReaderFrom); ok {
+		for {
+			n := segment
+			if rest := progressRemaining(progress); rest >= 0 && rest < n {
+				n = rest
+			}
+			if n == 0 {
+				// The quota is reached so the rest is checked by the buffered loop before it's written.
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			m, err := rf.ReadFrom(&io.LimitedReader{R: src, N: n})
+			written += m
+			if perr := progressUpdate(progress, m); perr != nil {
+				return written, perr
+			} else if err != nil {
+				return written, err
+			} else if m < n {
+				return written, nil
+			}
+		}
+	}
+
+	// Copy by the buffered loop
+	buf := make([]byte, size)
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		nr, er := src.Read(buf)
+		if nr > 0 {
+			if err := progressUpdate(progress, int64(nr)); err != nil {
+				return written, err
+			}
+			nw, ew := dst.Write(buf[:nr])
+			written += int64(nw)
+			if ew != nil {
+				return written, ew
+			} else if nw != nr {
+				return written, io.ErrShortWrite
+			}
+		}
+		if er == io.EOF {
+			return written, nil
+		} else if er != nil {
+			return written, er
+		}
+	}
+}
+
+// ParallelCopy copies from the given reader to the given writer until EOF or an error occurs by reading
+// into a buffer while the previous ones are being written. So the throughput is bounded by the slower of
+// the reader and the writer instead of the sum of their latencies (see io.Copy).
+// The given progress (if any) is updated before each write and the copy stops when the given context is
+// done or the progress is stopped by its controls or limits. Note that a read in progress can't be
+// interrupted so its goroutine exits after the read returns.
+// It returns the number of bytes written and the first error (if any) except io.EOF.
+func ParallelCopy(ctx context.Context, dst io.Writer, src io.Reader, progress *Progress, opts *CopyOptions) (written int64, err error) {
+	count, size := 2, 32*KiB
+	if opts != nil {
+		if opts.BufferCount > 2 {
+			count = opts.BufferCount
+		}
+		if opts.BufferSize > 0 {
+			size = opts.BufferSize
+		}
+	}
+
+	free := make(chan []byte, count)
+	for i := 0; i < count; i++ {
+		free <- make([]byte, size)
+	}
+	filled := make(chan parallelChunk, count)
+	done := make(chan struct{})
+	defer close(done)
+
+	// Read on a separate goroutine
+	go func() {
+		for {
+			var buf []byte
+			select {
+			case buf = <-free:
+			case <-done:
+				return
+			}
+			n, err := src.Read(buf)
+			select {
+			case filled <- parallelChunk{p: buf[:n], err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Write on the current goroutine
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		var c parallelChunk
+		select {
+		case c = <-filled:
+		case <-ctx.Done():
+			return written, ctx.Err()
+		}
+		if len(c.p) > 0 {
+			if err := progressUpdate(progress, int64(len(c.p))); err != nil {
+				return written, err
+			}
+			nw, ew := dst.Write(c.p)
+			written += int64(nw)
+			if ew != nil {
+				return written, ew
+			} else if nw != len(c.p) {
+				return written, io.ErrShortWrite
+			}
+		}
+		free <- c.p[:cap(c.p)]
+		if c.err == io.EOF {
+			return written, nil
+		} else if c.err != nil {
+			return written, c.err
+		}
+	}
+}
+
+// parallelChunk represents the result of a read by ParallelCopy.
+type parallelChunk struct {
+	p   []byte
+	err error
+}
+
+// progressUpdate updates the given progress (if any) by the given number of bytes.
+func progressUpdate(progress *Progress, n int64) error {
+	if progress == nil || n <= 0 {
+		return nil
+	}
+	return progress.update(n)
+}
+
+// progressRemaining returns the remaining quota of the given progress (if any) or -1 if there is no quota.
+func progressRemaining(progress *Progress) int64 {
+	if progress == nil {
+		return -1
+	}
+	return progress.remaining()
+}
@@ -0,0 +1,194 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestCopyWithProgress(t *testing.T) {
+	content := strings.Repeat("foo bar baz ", 1000)
+	opts := &streamy.CopyOptions{SegmentSize: streamy.KiB, BufferSize: 100}
+
+	table := []struct {
+		name  string
+		dst   func() io.Writer
+		src   func() io.Reader
+		quota int64
+		ctx   bool
+		want  int64
+		err   error
+	}{
+		{"ReaderFrom", func() io.Writer { return &bytes.Buffer{} }, func() io.Reader { return strings.NewReader(content) }, 0, false, int64(len(content)), nil},
+		{"WriterTo source", func() io.Writer { return writerOnly{&bytes.Buffer{}} }, func() io.Reader { return strings.NewReader(content) }, 0, false, int64(len(content)), nil},
+		{"Buffered", func() io.Writer { return writerOnly{&bytes.Buffer{}} }, func() io.Reader { return streamy.ReaderOnly(strings.NewReader(content)) }, 0, false, int64(len(content)), nil},
+		{"ReaderFrom quota", func() io.Writer { return &bytes.Buffer{} }, func() io.Reader { return strings.NewReader(content) }, 1500, false, 1500, streamy.ErrProgressQuotaExceeded},
+		{"ReaderFrom exact quota", func() io.Writer { return &bytes.Buffer{} }, func() io.Reader { return strings.NewReader(content) }, int64(len(content)), false, int64(len(content)), nil},
+		{"WriterTo source quota", func() io.Writer { return writerOnly{&bytes.Buffer{}} }, func() io.Reader { return strings.NewReader(content) }, 1500, false, 1500, streamy.ErrProgressQuotaExceeded},
+		{"Buffered quota", func() io.Writer { return writerOnly{&bytes.Buffer{}} }, func() io.Reader { return streamy.ReaderOnly(strings.NewReader(content)) }, 1500, false, 1500, streamy.ErrProgressQuotaExceeded},
+		{"ReaderFrom ctx", func() io.Writer { return &bytes.Buffer{} }, func() io.Reader { return strings.NewReader(content) }, 0, true, 0, context.Canceled},
+		{"Buffered ctx", func() io.Writer { return writerOnly{&bytes.Buffer{}} }, func() io.Reader { return streamy.ReaderOnly(strings.NewReader(content)) }, 0, true, 0, context.Canceled},
+	}
+	for _, v := range table {
+		ctx, cancel := context.WithCancel(context.Background())
+		if v.ctx {
+			cancel()
+		}
+		progress := streamy.Progress{}
+		if v.quota > 0 {
+			progress.SetQuota(v.quota, streamy.Byte)
+		}
+		dst := v.dst()
+		n, err := streamy.CopyWithProgress(ctx, dst, v.src(), &progress, opts)
+		cancel()
+		if !errors.Is(err, v.err) {
+			t.Errorf("%s: got %v, want %v", v.name, err, v.err)
+		} else if n != v.want {
+			t.Errorf("%s: got %v, want %v", v.name, n, v.want)
+		} else if progress.BytesWritten() != v.want {
+			t.Errorf("%s: got %v, want %v", v.name, progress.BytesWritten(), v.want)
+		} else if s := writtenString(dst); s != content[:v.want] {
+			t.Errorf("%s: got %v bytes, want %v bytes", v.name, len(s), v.want)
+		}
+	}
+}
+
+func TestCopyWithProgressFile(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("foo bar baz ", 100000)
+	if err := os.WriteFile(filepath.Join(dir, "src"), []byte(content), 0o600); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	src, err := os.Open(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	defer src.Close()
+	dst, err := os.Create(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	defer dst.Close()
+
+	progress := streamy.Progress{}
+	if err := progress.EnableStats(streamy.ProgressStatsModeSimple); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	if n, err := streamy.CopyWithProgress(context.Background(), dst, src, &progress, nil); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if n != int64(len(content)) {
+		t.Errorf("got %v, want %v", n, len(content))
+	} else if progress.BytesWritten() != n {
+		t.Errorf("got %v, want %v", progress.BytesWritten(), n)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "dst")); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if string(b) != content {
+		t.Errorf("got %v bytes, want %v bytes", len(b), len(content))
+	}
+}
+
+func TestCopyWithProgressStop(t *testing.T) {
+	progress := streamy.Progress{}
+	if err := progress.EnableControls(); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	progress.Stop()
+	r := streamy.ReaderOnly(strings.NewReader(strings.Repeat("foo bar baz ", 1000)))
+	if _, err := streamy.CopyWithProgress(context.Background(), io.Discard, r, &progress, &streamy.CopyOptions{SegmentSize: 10, BufferSize: 10}); err != streamy.ErrProgressStopped {
+		t.Errorf("got %v, want %v", err, streamy.ErrProgressStopped)
+	}
+}
+
+func BenchmarkCopyWithProgress(b *testing.B) {
+	content := strings.Repeat("foo bar baz ", 1000)
+	progress := streamy.Progress{}
+	for i := 0; i < b.N; i++ {
+		streamy.CopyWithProgress(context.Background(), &bytes.Buffer{}, strings.NewReader(content), &progress, nil)
+	}
+}
+
+func TestParallelCopy(t *testing.T) {
+	content := strings.Repeat("foo bar baz ", 1000)
+	errFoo := errors.New("foo")
+
+	table := []struct {
+		name  string
+		src   io.Reader
+		opts  *streamy.CopyOptions
+		quota int64
+		ctx   bool
+		fail  int
+		want  int64
+		err   error
+	}{
+		{"default", strings.NewReader(content), nil, 0, false, 0, int64(len(content)), nil},
+		{"small buffers", strings.NewReader(content), &streamy.CopyOptions{BufferSize: 100, BufferCount: 4}, 0, false, 0, int64(len(content)), nil},
+		{"one byte reader", iotest.OneByteReader(strings.NewReader(content)), &streamy.CopyOptions{BufferSize: 100}, 0, false, 0, int64(len(content)), nil},
+		{"reader error", iotest.TimeoutReader(strings.NewReader(content)), &streamy.CopyOptions{BufferSize: 100}, 0, false, 0, 100, iotest.ErrTimeout},
+		{"writer error", strings.NewReader(content), &streamy.CopyOptions{BufferSize: 100}, 0, false, 3, 300, errFoo},
+		{"quota", strings.NewReader(content), &streamy.CopyOptions{BufferSize: 100}, 1500, false, 0, 1500, streamy.ErrProgressQuotaExceeded},
+		{"ctx", strings.NewReader(content), nil, 0, true, 0, 0, context.Canceled},
+	}
+	for _, v := range table {
+		ctx, cancel := context.WithCancel(context.Background())
+		if v.ctx {
+			cancel()
+		}
+		progress := streamy.Progress{}
+		if v.quota > 0 {
+			progress.SetQuota(v.quota, streamy.Byte)
+		}
+		sw := &slowWriter{failAt: v.fail, err: errFoo}
+		n, err := streamy.ParallelCopy(ctx, sw, v.src, &progress, v.opts)
+		cancel()
+		if !errors.Is(err, v.err) {
+			t.Errorf("%s: got %v, want %v", v.name, err, v.err)
+		} else if n != v.want {
+			t.Errorf("%s: got %v, want %v", v.name, n, v.want)
+		} else if s := sw.String(); s != content[:v.want] {
+			t.Errorf("%s: got %v bytes, want %v bytes", v.name, len(s), v.want)
+		} else if v.fail == 0 && progress.BytesWritten() != v.want {
+			t.Errorf("%s: got %v, want %v", v.name, progress.BytesWritten(), v.want)
+		}
+	}
+}
+
+func BenchmarkParallelCopy(b *testing.B) {
+	opts := &streamy.CopyOptions{BufferSize: 4 * streamy.KiB}
+	for i := 0; i < b.N; i++ {
+		streamy.ParallelCopy(context.Background(), &slowWriter{delay: 100 * time.Microsecond}, &latencyReader{n: 20, delay: 100 * time.Microsecond}, nil, opts)
+	}
+}
+
+func BenchmarkParallelCopyPlain(b *testing.B) {
+	p := make([]byte, 4*streamy.KiB)
+	for i := 0; i < b.N; i++ {
+		io.CopyBuffer(&slowWriter{delay: 100 * time.Microsecond}, &latencyReader{n: 20, delay: 100 * time.Microsecond}, p)
+	}
+}
+
+// writerOnly hides the methods of a writer other than Write (i.e. io.ReaderFrom).
+type writerOnly struct {
+	io.Writer
+}
+
+// writtenString returns the bytes written to the given test writer as a string.
+func writtenString(w io.Writer) string {
+	if wo, ok := w.(writerOnly); ok {
+		w = wo.Writer
+	}
+	return w.(*bytes.Buffer).String()
+}
@@ -0,0 +1,114 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// AutoDecompress returns an io.ReadCloser that transparently decompresses the given reader.
+// It sniffs the leading bytes for gzip, bzip2 and zlib streams and returns the plain stream
+// if none of them matches or the header is invalid. The returned codec tells which decompression is applied.
+// Note that raw flate streams have no signature so they aren't detected (see NewDecompressReader).
+func AutoDecompress(r io.Reader) (io.ReadCloser, Codec, error) {
+	pr := NewPeekReader(r, detectSize)
+	b, err := pr.Peek(detectSize)
+	if err != nil && err != io.EOF {
+		return io.NopCloser(pr.Reader()), CodecNone, err
+	}
+	eof := err == io.EOF
+
+	// Decompress the stream by the detected codec
+	// The leading bytes are marked so the plain stream can be returned if the header is invalid.
+	codec := detectCodec(b, eof)
+	pr.Mark()
+	rc, err := NewDecompressReader(pr, codec)
+	if err != nil {
+		if pr.Reset() != nil {
+			return nil, codec, err
+		}
+		return io.NopCloser(pr.Reader()), CodecNone, nil
+	}
+	return rc, codec, nil
+}
+
+// NewDecompressReader returns an io.ReadCloser that decompresses the given reader by the given codec.
+// Unlike AutoDecompress, it can be used for the codecs that can't be detected (i.e. CodecFlate).
+func NewDecompressReader(r io.Reader, codec Codec) (io.ReadCloser, error) {
+	switch codec {
+	case CodecNone:
+		return io.NopCloser(r), nil
+	case CodecGzip:
+		rc, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return rc, nil
+	case CodecBzip2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case CodecZlib:
+		rc, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return rc, nil
+	case CodecFlate:
+		return flate.NewReader(r), nil
+	}
+	return nil, errors.New("unknown codec")
+}
+
+// detectCodec returns the compression codec of the given leading bytes.
+// The eof argument indicates whether the given bytes are the entire stream or not.
+func detectCodec(b []byte, eof bool) Codec {
+	switch detect(b, eof) {
+	case ContentTypeGzip:
+		return CodecGzip
+	case ContentTypeBzip2:
+		return CodecBzip2
+	case ContentTypeUnknown:
+		if isZlib(b) {
+			return CodecZlib
+		}
+	}
+	return CodecNone
+}
+
+// isZlib checks whether the given bytes start with a zlib header or not.
+// Ref: https://www.rfc-editor.org/rfc/rfc1950#section-2.2
+func isZlib(b []byte) bool {
+	if len(b) < 2 {
+		return false
+	}
+	cmf, flg := b[0], b[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
+// Codec represents a compression codec.
+type Codec struct {
+	name string
+}
+
+// String implements the fmt.Stringer interface.
+func (c Codec) String() string {
+	return c.name
+}
+
+var (
+	// CodecNone represents no compression (i.e. plain stream).
+	CodecNone = Codec{name: "none"}
+	// CodecGzip represents the gzip compression codec.
+	CodecGzip = Codec{name: "gzip"}
+	// CodecBzip2 represents the bzip2 compression codec.
+	CodecBzip2 = Codec{name: "bzip2"}
+	// CodecZlib represents the zlib compression codec.
+	CodecZlib = Codec{name: "zlib"}
+	// CodecFlate represents the flate (raw deflate) compression codec. It's never detected by AutoDecompress.
+	CodecFlate = Codec{name: "flate"}
+)
@@ -0,0 +1,121 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestAutoDecompress(t *testing.T) {
+	content := "foo bar baz"
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(content))
+	gw.Close()
+
+	var gzLarge bytes.Buffer
+	gw = gzip.NewWriter(&gzLarge)
+	gw.Write([]byte(strings.Repeat(content, 1000)))
+	gw.Close()
+
+	var zl bytes.Buffer
+	zw := zlib.NewWriter(&zl)
+	zw.Write([]byte(content))
+	zw.Close()
+
+	var fl bytes.Buffer
+	fw, _ := flate.NewWriter(&fl, flate.BestCompression)
+	fw.Write([]byte(content))
+	fw.Close()
+
+	bz, _ := hex.DecodeString("425a6839314159265359688b39bd00000311804000310090102000310c00941ea68f269190f177245385090688b39bd0")
+
+	table := []struct {
+		content []byte
+		codec   streamy.Codec
+		want    string
+	}{
+		{gz.Bytes(), streamy.CodecGzip, content},
+		{gzLarge.Bytes(), streamy.CodecGzip, strings.Repeat(content, 1000)},
+		{bz, streamy.CodecBzip2, content},
+		{zl.Bytes(), streamy.CodecZlib, content},
+		{fl.Bytes(), streamy.CodecNone, fl.String()},
+		{[]byte(content), streamy.CodecNone, content},
+		{[]byte("x^foo"), streamy.CodecNone, "x^foo"},
+		{[]byte("BZh is a plain text line\n"), streamy.CodecNone, "BZh is a plain text line\n"},
+		{[]byte{}, streamy.CodecNone, ""},
+		{[]byte{0x78, 0x20, 0x00, 0x01, 0x02, 0x03}, streamy.CodecNone, "\x78\x20\x00\x01\x02\x03"},
+		{[]byte{0x1f, 0x8b, 0x00, 0x01, 0x02, 0x03}, streamy.CodecNone, "\x1f\x8b\x00\x01\x02\x03"},
+		{[]byte{0x1f, 0x8b}, streamy.CodecNone, "\x1f\x8b"},
+	}
+	for _, v := range table {
+		rc, codec, err := streamy.AutoDecompress(streamy.ReaderOnly(bytes.NewReader(v.content)))
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+			continue
+		} else if codec != v.codec {
+			t.Errorf("got %v, want %v", codec, v.codec)
+		}
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(b) != v.want {
+			t.Errorf("got %v, want %v", string(b), v.want)
+		}
+		if err := rc.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	}
+}
+
+func TestNewDecompressReader(t *testing.T) {
+	content := strings.Repeat("foo bar baz", 1000)
+
+	var fl bytes.Buffer
+	fw, _ := flate.NewWriter(&fl, flate.BestSpeed)
+	fw.Write([]byte(content))
+	fw.Close()
+
+	table := []struct {
+		content []byte
+		codec   streamy.Codec
+		want    string
+	}{
+		{fl.Bytes(), streamy.CodecFlate, content},
+		{[]byte(content), streamy.CodecNone, content},
+	}
+	for _, v := range table {
+		rc, err := streamy.NewDecompressReader(bytes.NewReader(v.content), v.codec)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+			continue
+		}
+		if b, err := io.ReadAll(rc); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(b) != v.want {
+			t.Errorf("got %v bytes, want %v bytes", len(b), len(v.want))
+		}
+		rc.Close()
+	}
+	if _, err := streamy.NewDecompressReader(bytes.NewReader(nil), streamy.Codec{}); err == nil {
+		t.Errorf("got nil, want error")
+	}
+}
+
+func BenchmarkAutoDecompress(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rc, _, _ := streamy.AutoDecompress(bytes.NewBufferString("foo bar baz"))
+		io.Copy(io.Discard, rc)
+	}
+}
@@ -0,0 +1,103 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+// Describe returns the source information of the given reader.
+// Unlike HaveSeeker, the seekability is determined by probing the Seek method.
+func Describe(r io.Reader) Source {
+	src := Source{Kind: SourceKindUnknown, Size: -1}
+	switch v := r.(type) {
+	case *os.File:
+		describeFile(v, &src)
+	case net.Conn:
+		src.Kind = SourceKindSocket
+	case interface{ Len() int }:
+		// i.e. bytes.Buffer, bytes.Reader and strings.Reader
+		src.Kind = SourceKindBuffer
+		src.Size = int64(v.Len())
+	}
+	if s, ok := r.(io.Seeker); ok {
+		src.Seekable = probeSeek(s)
+	}
+	return src
+}
+
+// describeFile updates the given source information by the given file.
+func describeFile(f *os.File, src *Source) {
+	fi, err := f.Stat()
+	if err != nil {
+		return
+	}
+	mode := fi.Mode()
+	switch {
+	case mode.IsRegular():
+		src.Kind = SourceKindFile
+		src.Size = fi.Size()
+		// Subtract the current offset since the file might be partially read.
+		if off, err := f.Seek(0, io.SeekCurrent); err == nil && off <= src.Size {
+			src.Size -= off
+		}
+	case mode&os.ModeNamedPipe != 0:
+		// Anonymous pipes (i.e. stdin) don't have a path on the file system but FIFOs do.
+		src.Kind = SourceKindPipe
+		if lfi, err := os.Lstat(f.Name()); err == nil && lfi.Mode()&os.ModeNamedPipe != 0 {
+			src.Kind = SourceKindFIFO
+		}
+	case mode&os.ModeSocket != 0:
+		src.Kind = SourceKindSocket
+	case mode&os.ModeCharDevice != 0 && isTerminal(f):
+		src.Kind = SourceKindTerminal
+	case mode&os.ModeDevice != 0:
+		// i.e. /dev/null and block devices
+		src.Kind = SourceKindDevice
+	}
+}
+
+// probeSeek checks whether the given seeker can seek in practice or not.
+func probeSeek(s io.Seeker) bool {
+	_, err := s.Seek(0, io.SeekCurrent)
+	return err == nil
+}
+
+// Source represents the information of a stream source.
+type Source struct {
+	Kind     SourceKind
+	Size     int64 // Size is the number of bytes that can be read or -1 if it's unknown.
+	Seekable bool
+}
+
+// SourceKind represents a kind of stream source.
+type SourceKind struct {
+	name string
+}
+
+// String implements the fmt.Stringer interface.
+func (sk SourceKind) String() string {
+	return sk.name
+}
+
+var (
+	// SourceKindUnknown represents an unknown source.
+	SourceKindUnknown = SourceKind{name: "unknown"}
+	// SourceKindFile represents a regular file.
+	SourceKindFile = SourceKind{name: "file"}
+	// SourceKindPipe represents an anonymous pipe (i.e. piped stdin).
+	SourceKindPipe = SourceKind{name: "pipe"}
+	// SourceKindFIFO represents a named pipe.
+	SourceKindFIFO = SourceKind{name: "fifo"}
+	// SourceKindSocket represents a socket.
+	SourceKindSocket = SourceKind{name: "socket"}
+	// SourceKindTerminal represents a terminal.
+	SourceKindTerminal = SourceKind{name: "terminal"}
+	// SourceKindDevice represents a device other than a terminal (i.e. /dev/null or a block device).
+	SourceKindDevice = SourceKind{name: "device"}
+	// SourceKindBuffer represents an in-memory buffer.
+	SourceKindBuffer = SourceKind{name: "buffer"}
+)
@@ -0,0 +1,11 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package streamy
+
+import "syscall"
+
+// ioctlGetTermios is the ioctl request for getting the terminal attributes.
+const ioctlGetTermios = syscall.TIOCGETA
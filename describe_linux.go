@@ -0,0 +1,9 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import "syscall"
+
+// ioctlGetTermios is the ioctl request for getting the terminal attributes.
+const ioctlGetTermios = syscall.TCGETS
@@ -0,0 +1,14 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package streamy
+
+import "os"
+
+// isTerminal checks whether the given file is a terminal or not.
+// Since it can't be determined on this platform, it always returns false.
+func isTerminal(f *os.File) bool {
+	return false
+}
@@ -0,0 +1,71 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestDescribe(t *testing.T) {
+	f, _ := os.Open("describe_test.go")
+	defer f.Close()
+	fi, _ := f.Stat()
+
+	fp, _ := os.Open("describe_test.go")
+	defer fp.Close()
+	fp.Seek(10, io.SeekStart)
+
+	pr, pw, _ := os.Pipe()
+	defer pr.Close()
+	defer pw.Close()
+
+	dn, _ := os.Open(os.DevNull)
+	defer dn.Close()
+
+	cr, cw := net.Pipe()
+	defer cr.Close()
+	defer cw.Close()
+
+	table := []struct {
+		reader   io.Reader
+		kind     streamy.SourceKind
+		size     int64
+		seekable bool
+	}{
+		{f, streamy.SourceKindFile, fi.Size(), true},
+		{fp, streamy.SourceKindFile, fi.Size() - 10, true},
+		{pr, streamy.SourceKindPipe, -1, false},
+		{cr, streamy.SourceKindSocket, -1, false},
+		{dn, streamy.SourceKindDevice, -1, true},
+		{bytes.NewBufferString("foo"), streamy.SourceKindBuffer, 3, false},
+		{bytes.NewReader([]byte("foo")), streamy.SourceKindBuffer, 3, true},
+		{strings.NewReader("foo"), streamy.SourceKindBuffer, 3, true},
+		{streamy.ReaderOnly(strings.NewReader("foo")), streamy.SourceKindUnknown, -1, false},
+	}
+	for _, v := range table {
+		src := streamy.Describe(v.reader)
+		if src.Kind != v.kind {
+			t.Errorf("got %v, want %v", src.Kind, v.kind)
+		} else if src.Size != v.size {
+			t.Errorf("got %v, want %v", src.Size, v.size)
+		} else if src.Seekable != v.seekable {
+			t.Errorf("got %v, want %v", src.Seekable, v.seekable)
+		}
+	}
+}
+
+func BenchmarkDescribe(b *testing.B) {
+	f, _ := os.Open("describe_test.go")
+	defer f.Close()
+	for i := 0; i < b.N; i++ {
+		streamy.Describe(f)
+	}
+}
@@ -0,0 +1,27 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package streamy
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal checks whether the given file is a terminal or not (i.e. isatty).
+// The ioctl is made by the raw connection since the Fd method switches the file to the blocking mode.
+func isTerminal(f *os.File) bool {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+	var errno syscall.Errno
+	err = rc.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&termios)))
+	})
+	return err == nil && errno == 0
+}
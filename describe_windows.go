@@ -0,0 +1,24 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"os"
+	"syscall"
+)
+
+// isTerminal checks whether the given file is a console or not.
+// The handle is used by the raw connection since the Fd method switches the file to the blocking mode.
+func isTerminal(f *os.File) bool {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+	var cerr error
+	err = rc.Control(func(fd uintptr) {
+		var mode uint32
+		cerr = syscall.GetConsoleMode(syscall.Handle(fd), &mode)
+	})
+	return err == nil && cerr == nil
+}
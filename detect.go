@@ -0,0 +1,192 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// detectSize is the number of bytes used for detecting content types.
+const detectSize = 1024
+
+// Detect detects the content type of the given reader by peeking at its leading bytes.
+// It returns the content type and an io.Reader that yields the full original stream.
+func Detect(r io.Reader) (ContentType, io.Reader, error) {
+	pr := NewPeekReader(r, detectSize)
+	b, err := pr.Peek(detectSize)
+	if err != nil && err != io.EOF {
+		return ContentTypeUnknown, pr.Reader(), err
+	}
+	return detect(b, err == io.EOF), pr.Reader(), nil
+}
+
+// detect returns the content type of the given leading bytes.
+// The eof argument indicates whether the given bytes are the entire stream or not.
+func detect(b []byte, eof bool) ContentType {
+	// Check the signatures
+	switch {
+	case bytes.HasPrefix(b, []byte{0x1f, 0x8b}):
+		return ContentTypeGzip
+	case bytes.HasPrefix(b, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return ContentTypeZstd
+	case isBzip2(b):
+		return ContentTypeBzip2
+	case bytes.HasPrefix(b, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return ContentTypeXz
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")), bytes.HasPrefix(b, []byte("PK\x05\x06")):
+		return ContentTypeZip
+	case len(b) >= 262 && bytes.Equal(b[257:262], []byte("ustar")):
+		return ContentTypeTar
+	case bytes.HasPrefix(b, []byte{0xef, 0xbb, 0xbf}):
+		return ContentTypeUTF8BOM
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		return ContentTypeUTF16LE
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		return ContentTypeUTF16BE
+	}
+
+	// Check the text based formats
+	if len(b) == 0 || !isText(b, eof) {
+		return ContentTypeUnknown
+	}
+	trimmed := bytes.TrimLeft(b, " \t\r\n")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if isNDJSON(trimmed) {
+			return ContentTypeNDJSON
+		} else if isJSON(trimmed, eof) {
+			return ContentTypeJSON
+		}
+	}
+	if isCSV(b, eof) {
+		return ContentTypeCSV
+	}
+	return ContentTypeText
+}
+
+// isBzip2 checks whether the given bytes are the beginning of a bzip2 stream or not.
+// Besides the "BZh" signature, it checks the block size and the magic of the first block
+// (or the end of stream magic for the empty streams) since the signature is a plain text.
+func isBzip2(b []byte) bool {
+	if len(b) < 10 || !bytes.HasPrefix(b, []byte("BZh")) || b[3] < '1' || b[3] > '9' {
+		return false
+	}
+	return bytes.Equal(b[4:10], []byte("1AY&SY")) || bytes.Equal(b[4:10], []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90})
+}
+
+// isText checks whether the given bytes are UTF-8 text or not.
+// Note that an incomplete rune at the end is ignored unless the bytes are the entire stream.
+func isText(b []byte, eof bool) bool {
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c < utf8.RuneSelf {
+			if c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' {
+				return false
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return !eof && !utf8.FullRune(b[i:])
+		}
+		i += size
+	}
+	return true
+}
+
+// isJSON checks whether the given bytes are a JSON value or not.
+// If the bytes aren't the entire stream then they are scanned by tokens so an incomplete value is accepted.
+func isJSON(b []byte, eof bool) bool {
+	if eof {
+		return json.Valid(b)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for {
+		if _, err := dec.Token(); err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+// isNDJSON checks whether the given bytes consist of newline delimited JSON values or not.
+func isNDJSON(b []byte) bool {
+	lines := bytes.Split(b, []byte{'\n'})
+	if len(lines) < 2 || !json.Valid(lines[0]) {
+		return false
+	}
+	// The last line might be incomplete so only the next non-empty line is checked.
+	for _, line := range lines[1:] {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		return line[0] == '{' || line[0] == '['
+	}
+	return false
+}
+
+// isCSV checks whether the given bytes consist of comma separated values or not.
+func isCSV(b []byte, eof bool) bool {
+	if !eof {
+		// Drop the last line since it might be incomplete.
+		i := bytes.LastIndexByte(b, '\n')
+		if i < 0 {
+			return false
+		}
+		b = b[:i+1]
+	}
+	cr := csv.NewReader(bytes.NewReader(b))
+	records, err := cr.ReadAll()
+	if err != nil || len(records) < 2 {
+		return false
+	}
+	return len(records[0]) > 1
+}
+
+// ContentType represents a content type.
+type ContentType struct {
+	name string
+}
+
+// String implements the fmt.Stringer interface.
+func (ct ContentType) String() string {
+	return ct.name
+}
+
+var (
+	// ContentTypeUnknown represents an unknown (i.e. binary) content type.
+	ContentTypeUnknown = ContentType{name: "unknown"}
+	// ContentTypeGzip represents the gzip content type.
+	ContentTypeGzip = ContentType{name: "gzip"}
+	// ContentTypeZstd represents the zstd content type.
+	ContentTypeZstd = ContentType{name: "zstd"}
+	// ContentTypeBzip2 represents the bzip2 content type.
+	ContentTypeBzip2 = ContentType{name: "bzip2"}
+	// ContentTypeXz represents the xz content type.
+	ContentTypeXz = ContentType{name: "xz"}
+	// ContentTypeZip represents the zip content type.
+	ContentTypeZip = ContentType{name: "zip"}
+	// ContentTypeTar represents the tar content type.
+	ContentTypeTar = ContentType{name: "tar"}
+	// ContentTypeJSON represents the JSON content type.
+	ContentTypeJSON = ContentType{name: "json"}
+	// ContentTypeNDJSON represents the newline delimited JSON content type.
+	ContentTypeNDJSON = ContentType{name: "ndjson"}
+	// ContentTypeCSV represents the CSV content type.
+	ContentTypeCSV = ContentType{name: "csv"}
+	// ContentTypeText represents the UTF-8 text content type.
+	ContentTypeText = ContentType{name: "text"}
+	// ContentTypeUTF8BOM represents the UTF-8 text (with BOM) content type.
+	ContentTypeUTF8BOM = ContentType{name: "utf-8-bom"}
+	// ContentTypeUTF16LE represents the UTF-16 little endian text (with BOM) content type.
+	ContentTypeUTF16LE = ContentType{name: "utf-16le"}
+	// ContentTypeUTF16BE represents the UTF-16 big endian text (with BOM) content type.
+	ContentTypeUTF16BE = ContentType{name: "utf-16be"}
+)
@@ -0,0 +1,84 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestDetect(t *testing.T) {
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte("foo"))
+	gw.Close()
+
+	var zp bytes.Buffer
+	zw := zip.NewWriter(&zp)
+	zw.Create("foo")
+	zw.Close()
+
+	var tr bytes.Buffer
+	tw := tar.NewWriter(&tr)
+	tw.WriteHeader(&tar.Header{Name: "foo", Mode: 0600})
+	tw.Close()
+
+	table := []struct {
+		content []byte
+		want    streamy.ContentType
+	}{
+		{gz.Bytes(), streamy.ContentTypeGzip},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, streamy.ContentTypeZstd},
+		{[]byte("BZh91AY&SY"), streamy.ContentTypeBzip2},
+		{[]byte("BZh9\x17\x72\x45\x38\x50\x90\x00\x00\x00\x00"), streamy.ContentTypeBzip2},
+		{[]byte("BZh is a plain text line\n"), streamy.ContentTypeText},
+		{[]byte("BZh91 is a plain text line\n"), streamy.ContentTypeText},
+		{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00}, streamy.ContentTypeXz},
+		{zp.Bytes(), streamy.ContentTypeZip},
+		{tr.Bytes(), streamy.ContentTypeTar},
+		{[]byte(`{"foo": "bar"}`), streamy.ContentTypeJSON},
+		{[]byte("[\n  1,\n  2\n]\n"), streamy.ContentTypeJSON},
+		{[]byte("{\"foo\": 1}\n{\"foo\": 2}\n"), streamy.ContentTypeNDJSON},
+		{[]byte(`{"foo": "` + strings.Repeat("bar", 1000) + `"}`), streamy.ContentTypeJSON},
+		{[]byte("[INFO] server started\n[INFO] listening on :8080\n"), streamy.ContentTypeText},
+		{[]byte(strings.Repeat("[INFO] server started\n", 100)), streamy.ContentTypeText},
+		{[]byte("{not json"), streamy.ContentTypeText},
+		{[]byte("foo,bar\n1,2\n3,4\n"), streamy.ContentTypeCSV},
+		{[]byte("foo bar baz\n"), streamy.ContentTypeText},
+		{[]byte("\xef\xbb\xbffoo"), streamy.ContentTypeUTF8BOM},
+		{[]byte("\xff\xfef\x00o\x00o\x00"), streamy.ContentTypeUTF16LE},
+		{[]byte("\xfe\xff\x00f\x00o\x00o"), streamy.ContentTypeUTF16BE},
+		{[]byte{0x00, 0x01, 0x02}, streamy.ContentTypeUnknown},
+		{[]byte{}, streamy.ContentTypeUnknown},
+		{[]byte(strings.Repeat("foo,bar\n", 200)), streamy.ContentTypeCSV},
+		{[]byte(strings.Repeat("ğ", 1000)), streamy.ContentTypeText},
+	}
+	for _, v := range table {
+		ct, r, err := streamy.Detect(streamy.ReaderOnly(bytes.NewReader(v.content)))
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if ct != v.want {
+			t.Errorf("got %v, want %v", ct, v.want)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.content) {
+			t.Errorf("got %v, want %v", b, v.content)
+		}
+	}
+}
+
+func BenchmarkDetect(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		streamy.Detect(bytes.NewBufferString("{\"foo\": 1}\n{\"foo\": 2}\n"))
+	}
+}
@@ -0,0 +1,135 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// NewFollowReader returns a FollowReader that reads the given file and keeps reading past EOF until
+// the given context is cancelled (i.e. `tail -f`).
+func NewFollowReader(ctx context.Context, name string) (*FollowReader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowReader{ctx: ctx, name: name, f: f, interval: 250 * time.Millisecond}, nil
+}
+
+// FollowReader implements the io.ReadCloser interface for following growing files.
+// It polls the file for new bytes and handles truncation and rotation (i.e. file replaced by a new one).
+// Since it never returns io.EOF, it can be used with Index for waiting a marker to appear.
+type FollowReader struct {
+	ctx      context.Context
+	name     string
+	f        *os.File
+	offset   int64
+	interval time.Duration
+}
+
+// SetPollInterval sets the poll interval. The default interval is 250 milliseconds.
+func (fr *FollowReader) SetPollInterval(d time.Duration) {
+	fr.interval = d
+}
+
+// Read implements the io.Reader interface.
+// It blocks until there are new bytes or the context is cancelled, in that case it returns the context error.
+func (fr *FollowReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var timer *time.Timer
+	for {
+		if err := fr.ctx.Err(); err != nil {
+			return 0, err
+		}
+		n, err = fr.f.Read(p)
+		fr.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		// Reached EOF so check whether the file is changed or not
+		if changed, err := fr.check(); err != nil {
+			return 0, err
+		} else if changed {
+			continue
+		}
+
+		// Wait for the next poll
+		if timer == nil {
+			timer = time.NewTimer(fr.interval)
+			defer timer.Stop()
+		} else {
+			timer.Reset(fr.interval)
+		}
+		select {
+		case <-fr.ctx.Done():
+			return 0, fr.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Close implements the io.Closer interface.
+func (fr *FollowReader) Close() error {
+	return fr.f.Close()
+}
+
+// check checks whether the file is truncated or rotated and if so then it starts reading from the beginning.
+// On rotation, the old file is drained to EOF before switching to the new one (i.e. `tail -F`).
+func (fr *FollowReader) check() (bool, error) {
+	fi, err := os.Stat(fr.name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// The file might be rotated but not created yet.
+			return false, nil
+		}
+		return false, err
+	}
+	cfi, err := fr.f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	// Rotation (i.e. inode change)
+	if !os.SameFile(fi, cfi) {
+		f, err := os.Open(fr.name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		// Check the old file again after opening the new one so the bytes written to the old file
+		// until now aren't lost.
+		if cfi, err = fr.f.Stat(); err != nil {
+			f.Close()
+			return false, err
+		} else if cfi.Size() > fr.offset {
+			f.Close()
+			return true, nil
+		}
+		fr.f.Close()
+		fr.f = f
+		fr.offset = 0
+		return true, nil
+	}
+
+	// Truncation
+	if cfi.Size() < fr.offset {
+		if _, err := fr.f.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		fr.offset = 0
+		return true, nil
+	}
+	return false, nil
+}
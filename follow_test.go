@@ -0,0 +1,125 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestFollowReader(t *testing.T) {
+	delay := 10 * time.Millisecond
+	table := []struct {
+		writes []func(name string)
+		want   string
+	}{
+		{
+			writes: []func(name string){
+				func(name string) { appendFile(name, "foo ") },
+				func(name string) { appendFile(name, "bar ") },
+				func(name string) { appendFile(name, "baz") },
+			},
+			want: "foo bar baz",
+		},
+		{
+			writes: []func(name string){
+				func(name string) { appendFile(name, "foo ") },
+				func(name string) { os.Truncate(name, 0) },
+				func(name string) { appendFile(name, "baz") },
+			},
+			want: "foo baz",
+		},
+		{
+			writes: []func(name string){
+				func(name string) { appendFile(name, "foo ") },
+				func(name string) { os.Rename(name, name+".1") },
+				func(name string) { appendFile(name, "baz") },
+			},
+			want: "foo baz",
+		},
+		{
+			writes: []func(name string){
+				func(name string) { appendFile(name, "foo ") },
+				func(name string) {
+					// The old file is written after the rotation (i.e. by a writer that holds it).
+					os.Rename(name, name+".1")
+					appendFile(name+".1", "bar ")
+					appendFile(name, "baz")
+				},
+			},
+			want: "foo bar baz",
+		},
+	}
+	for _, v := range table {
+		name := filepath.Join(t.TempDir(), "foo.log")
+		os.WriteFile(name, nil, 0600)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		fr, err := streamy.NewFollowReader(ctx, name)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+			cancel()
+			continue
+		}
+		fr.SetPollInterval(delay / 2)
+		go func(writes []func(name string)) {
+			for _, w := range writes {
+				time.Sleep(delay * 2)
+				w(name)
+			}
+			time.Sleep(delay * 4)
+			cancel()
+		}(v.writes)
+		b, err := io.ReadAll(fr)
+		if err != context.Canceled {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		} else if string(b) != v.want {
+			t.Errorf("got %q, want %q", b, v.want)
+		}
+		fr.Close()
+	}
+}
+
+func TestFollowReaderIndex(t *testing.T) {
+	delay := 10 * time.Millisecond
+	name := filepath.Join(t.TempDir(), "foo.log")
+	os.WriteFile(name, []byte("foo "), 0600)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	fr, err := streamy.NewFollowReader(ctx, name)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	defer fr.Close()
+	fr.SetPollInterval(delay)
+	go func() {
+		time.Sleep(delay * 2)
+		appendFile(name, "bar ")
+		time.Sleep(delay * 2)
+		appendFile(name, "ready baz")
+	}()
+	index, _, err := streamy.Index(fr, []byte("ready"), 1)
+	if err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if index != 8 {
+		t.Errorf("got %v, want %v", index, 8)
+	}
+}
+
+// appendFile appends the given content to the given file.
+func appendFile(name, content string) {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(content)
+}
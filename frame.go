@@ -0,0 +1,307 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	// ErrFrameTooLarge means that a frame exceeds the maximum frame size.
+	ErrFrameTooLarge = errors.New("frame too large")
+	// ErrFrameTruncated means that a frame ends before it's complete.
+	ErrFrameTruncated = errors.New("frame truncated")
+)
+
+// defaultMaxFrameSize is the default maximum frame size.
+const defaultMaxFrameSize = 4 * MiB
+
+// NewFrameWriter returns a FrameWriter that writes length-prefixed frames by the given format.
+func NewFrameWriter(w io.Writer, format FrameFormat) *FrameWriter {
+	return &FrameWriter{w: w, format: format, max: int64(defaultMaxFrameSize)}
+}
+
+// NewDelimitedFrameWriter returns a FrameWriter that writes frames followed by the given delimiter.
+// It returns an error if the delimiter is empty.
+func NewDelimitedFrameWriter(w io.Writer, delim []byte) (*FrameWriter, error) {
+	if len(delim) == 0 {
+		return nil, errors.New("empty delimiter")
+	}
+	return &FrameWriter{w: w, format: frameFormatDelimited, delim: delim, max: int64(defaultMaxFrameSize)}, nil
+}
+
+// FrameWriter implements the io.Writer interface for writing frames. Each write call writes one frame.
+type FrameWriter struct {
+	w      io.Writer
+	format FrameFormat
+	delim  []byte
+	max    int64
+}
+
+// SetMaxFrameSize sets the maximum frame size. The default size is 4 MiB.
+func (fw *FrameWriter) SetMaxFrameSize(size BinaryUnit) {
+	fw.max = int64(size)
+}
+
+// Write implements the io.Writer interface.
+func (fw *FrameWriter) Write(p []byte) (n int, err error) {
+	if err := fw.WriteFrame(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteFrame writes the given bytes as a frame.
+func (fw *FrameWriter) WriteFrame(p []byte) error {
+	size := int64(len(p))
+	if size > fw.max || size > fw.format.maxSize() {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+
+	// Build the frame so it's written by one write call
+	var frame []byte
+	switch fw.format {
+	case FrameFormatUvarint:
+		frame = binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(p)), uint64(size))
+	case FrameFormatUint16BE:
+		frame = binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(p)), uint16(size))
+	case FrameFormatUint16LE:
+		frame = binary.LittleEndian.AppendUint16(make([]byte, 0, 2+len(p)), uint16(size))
+	case FrameFormatUint32BE:
+		frame = binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(p)), uint32(size))
+	case FrameFormatUint32LE:
+		frame = binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(p)), uint32(size))
+	case frameFormatDelimited:
+		if bytes.Contains(p, fw.delim) {
+			return errors.New("frame contains delimiter")
+		}
+		frame = make([]byte, 0, len(p)+len(fw.delim))
+	}
+	frame = append(frame, p...)
+	frame = append(frame, fw.delim...)
+	_, err := fw.w.Write(frame)
+	return err
+}
+
+// NewFrameReader returns a FrameReader that reads length-prefixed frames by the given format.
+func NewFrameReader(r io.Reader, format FrameFormat) *FrameReader {
+	return &FrameReader{r: &pushbackReader{r: r}, format: format, max: int64(defaultMaxFrameSize)}
+}
+
+// NewDelimitedFrameReader returns a FrameReader that reads frames separated by the given delimiter.
+// It returns an error if the delimiter is empty.
+func NewDelimitedFrameReader(r io.Reader, delim []byte) (*FrameReader, error) {
+	if len(delim) == 0 {
+		return nil, errors.New("empty delimiter")
+	}
+	return &FrameReader{r: &pushbackReader{r: r}, format: frameFormatDelimited, delim: delim, max: int64(defaultMaxFrameSize)}, nil
+}
+
+// FrameReader represents a reader for reading frames.
+type FrameReader struct {
+	r      *pushbackReader
+	format FrameFormat
+	delim  []byte
+	max    int64
+	err    error
+	buf    []byte // buf is the scan buffer of the delimited frames and buf[off:] is not consumed yet.
+	off    int
+}
+
+// SetMaxFrameSize sets the maximum frame size. The default size is 4 MiB.
+func (fr *FrameReader) SetMaxFrameSize(size BinaryUnit) {
+	fr.max = int64(size)
+}
+
+// ReadFrame reads the next frame. It returns io.EOF when there are no more frames.
+// Oversize and truncated frames return errors that wrap ErrFrameTooLarge and ErrFrameTruncated.
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	if fr.err != nil {
+		return nil, fr.err
+	}
+	var p []byte
+	var err error
+	if fr.format == frameFormatDelimited {
+		p, err = fr.readDelimited()
+	} else {
+		p, err = fr.readPrefixed()
+	}
+	if err != nil {
+		// The stream can't be trusted after an error so it's sticky.
+		fr.err = err
+	}
+	return p, err
+}
+
+// readPrefixed reads a length-prefixed frame.
+func (fr *FrameReader) readPrefixed() ([]byte, error) {
+	var size uint64
+	var err error
+	if fr.format == FrameFormatUvarint {
+		size, err = binary.ReadUvarint(fr.r)
+	} else {
+		hdr := make([]byte, fr.format.prefixSize())
+		if _, err = io.ReadFull(fr.r, hdr); err == nil {
+			switch fr.format {
+			case FrameFormatUint16BE:
+				size = uint64(binary.BigEndian.Uint16(hdr))
+			case FrameFormatUint16LE:
+				size = uint64(binary.LittleEndian.Uint16(hdr))
+			case FrameFormatUint32BE:
+				size = uint64(binary.BigEndian.Uint32(hdr))
+			case FrameFormatUint32LE:
+				size = uint64(binary.LittleEndian.Uint32(hdr))
+			}
+		}
+	}
+	if err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%w: incomplete length prefix", ErrFrameTruncated)
+	} else if err != nil {
+		return nil, err
+	}
+	if size > uint64(fr.max) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+	p := make([]byte, size)
+	if n, err := io.ReadFull(fr.r, p); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d bytes", ErrFrameTruncated, n, size)
+	} else if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// readDelimited reads a delimited frame by scanning the delimiter.
+// Unlike Index, it keeps the bytes read past the delimiter in the scan buffer for the next frame,
+// and continues the scan where it's left (instead of rescanning the frame) when more bytes are read.
+func (fr *FrameReader) readDelimited() ([]byte, error) {
+	scanned := 0 // scanned is the number of the unconsumed bytes that don't contain the delimiter.
+	for {
+		data := fr.buf[fr.off:]
+		if i := bytes.Index(data[scanned:], fr.delim); i >= 0 {
+			i += scanned
+			if int64(i) > fr.max {
+				return nil, fmt.Errorf("%w: more than %d bytes", ErrFrameTooLarge, fr.max)
+			}
+			frame := make([]byte, i)
+			copy(frame, data)
+			fr.off += i + len(fr.delim)
+			return frame, nil
+		}
+		if int64(len(data)) >= fr.max+int64(len(fr.delim)) {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrFrameTooLarge, fr.max)
+		}
+		if scanned = len(data) - len(fr.delim) + 1; scanned < 0 {
+			scanned = 0
+		}
+
+		// Move the unconsumed bytes to the beginning and grow the buffer if it's full
+		if fr.off > 0 {
+			fr.buf = fr.buf[:copy(fr.buf, data)]
+			fr.off = 0
+		}
+		if len(fr.buf) == cap(fr.buf) {
+			buf := make([]byte, len(fr.buf), 2*cap(fr.buf)+4096)
+			copy(buf, fr.buf)
+			fr.buf = buf
+		}
+
+		// Read more
+		n, err := fr.r.Read(fr.buf[len(fr.buf):cap(fr.buf)])
+		fr.buf = fr.buf[:len(fr.buf)+n]
+		if n > 0 {
+			continue
+		} else if err == io.EOF {
+			if len(fr.buf) == 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("%w: missing delimiter", ErrFrameTruncated)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pushbackReader implements an io.Reader that allows pushing back the bytes that are read.
+type pushbackReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// Read implements the io.Reader interface.
+func (pr *pushbackReader) Read(p []byte) (n int, err error) {
+	if len(pr.buf) > 0 {
+		n = copy(p, pr.buf)
+		pr.buf = pr.buf[n:]
+		return n, nil
+	}
+	return pr.r.Read(p)
+}
+
+// ReadByte implements the io.ByteReader interface.
+func (pr *pushbackReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(pr, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// Unread pushes back the given bytes so they are read by the next read calls.
+func (pr *pushbackReader) Unread(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	buf := make([]byte, 0, len(p)+len(pr.buf))
+	buf = append(buf, p...)
+	pr.buf = append(buf, pr.buf...)
+}
+
+// FrameFormat represents a frame format (i.e. length prefix).
+type FrameFormat struct {
+	format uint8
+}
+
+// prefixSize returns the size of the fixed length prefix.
+func (ff FrameFormat) prefixSize() int {
+	switch ff {
+	case FrameFormatUint16BE, FrameFormatUint16LE:
+		return 2
+	case FrameFormatUint32BE, FrameFormatUint32LE:
+		return 4
+	}
+	return 0
+}
+
+// maxSize returns the maximum frame size that can be represented by the format.
+func (ff FrameFormat) maxSize() int64 {
+	switch ff {
+	case FrameFormatUint16BE, FrameFormatUint16LE:
+		return math.MaxUint16
+	case FrameFormatUint32BE, FrameFormatUint32LE:
+		return math.MaxUint32
+	}
+	return math.MaxInt64
+}
+
+var (
+	// FrameFormatUvarint represents the unsigned varint length prefix format.
+	FrameFormatUvarint = FrameFormat{format: 0}
+	// FrameFormatUint16BE represents the big endian uint16 length prefix format.
+	FrameFormatUint16BE = FrameFormat{format: 1}
+	// FrameFormatUint16LE represents the little endian uint16 length prefix format.
+	FrameFormatUint16LE = FrameFormat{format: 2}
+	// FrameFormatUint32BE represents the big endian uint32 length prefix format.
+	FrameFormatUint32BE = FrameFormat{format: 3}
+	// FrameFormatUint32LE represents the little endian uint32 length prefix format.
+	FrameFormatUint32LE = FrameFormat{format: 4}
+
+	// frameFormatDelimited represents the delimiter based format (see NewDelimitedFrameWriter).
+	frameFormatDelimited = FrameFormat{format: 5}
+)
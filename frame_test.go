@@ -0,0 +1,140 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestFrame(t *testing.T) {
+	table := []struct {
+		format streamy.FrameFormat
+		delim  []byte
+		frames []string
+	}{
+		{streamy.FrameFormatUvarint, nil, []string{"foo", "", "bar baz", strings.Repeat("qux", 1000)}},
+		{streamy.FrameFormatUint16BE, nil, []string{"foo", "", "bar baz"}},
+		{streamy.FrameFormatUint16LE, nil, []string{"foo", "", "bar baz"}},
+		{streamy.FrameFormatUint32BE, nil, []string{"foo", "", "bar baz"}},
+		{streamy.FrameFormatUint32LE, nil, []string{"foo", "", "bar baz"}},
+		{streamy.FrameFormat{}, []byte("\n"), []string{"foo", "", "bar baz", strings.Repeat("qux", 10000)}},
+		{streamy.FrameFormat{}, []byte("\r\n"), []string{"foo", "bar\rbaz", "qux\n"}},
+		{streamy.FrameFormat{}, []byte{0x00}, []string{"foo", "bar"}},
+	}
+	for _, v := range table {
+		var buf bytes.Buffer
+		var fw *streamy.FrameWriter
+		if v.delim != nil {
+			fw, _ = streamy.NewDelimitedFrameWriter(&buf, v.delim)
+		} else {
+			fw = streamy.NewFrameWriter(&buf, v.format)
+		}
+		for _, s := range v.frames {
+			if err := fw.WriteFrame([]byte(s)); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+		}
+
+		// Read by short reads to make sure the frames span multiple reads.
+		var fr *streamy.FrameReader
+		if v.delim != nil {
+			fr, _ = streamy.NewDelimitedFrameReader(iotest.HalfReader(&buf), v.delim)
+		} else {
+			fr = streamy.NewFrameReader(iotest.HalfReader(&buf), v.format)
+		}
+		for _, s := range v.frames {
+			p, err := fr.ReadFrame()
+			if err != nil {
+				t.Errorf("got %v, want nil", err)
+			} else if string(p) != s {
+				t.Errorf("got %q, want %q", p, s)
+			}
+		}
+		if _, err := fr.ReadFrame(); err != io.EOF {
+			t.Errorf("got %v, want %v", err, io.EOF)
+		}
+	}
+}
+
+func TestFrameErrors(t *testing.T) {
+	table := []struct {
+		reader *streamy.FrameReader
+		max    streamy.BinaryUnit
+		err    error
+	}{
+		{streamy.NewFrameReader(bytes.NewReader([]byte{0x00, 0x05, 'f', 'o'}), streamy.FrameFormatUint16BE), 0, streamy.ErrFrameTruncated},
+		{streamy.NewFrameReader(bytes.NewReader([]byte{0x00}), streamy.FrameFormatUint16BE), 0, streamy.ErrFrameTruncated},
+		{streamy.NewFrameReader(bytes.NewReader([]byte{0x80}), streamy.FrameFormatUvarint), 0, streamy.ErrFrameTruncated},
+		{streamy.NewFrameReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}), streamy.FrameFormatUint32LE), 0, streamy.ErrFrameTooLarge},
+		{streamy.NewFrameReader(bytes.NewReader([]byte{0x04, 'f', 'o', 'o', ' '}), streamy.FrameFormatUvarint), 3, streamy.ErrFrameTooLarge},
+		{newLineFrameReader("foo"), 0, streamy.ErrFrameTruncated},
+		{newLineFrameReader(strings.Repeat("foo", 2000) + "\n"), 4 * streamy.KiB, streamy.ErrFrameTooLarge},
+	}
+	for _, v := range table {
+		if v.max > 0 {
+			v.reader.SetMaxFrameSize(v.max)
+		}
+		_, err := v.reader.ReadFrame()
+		if !errors.Is(err, v.err) {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+		if _, err2 := v.reader.ReadFrame(); err2 != err {
+			t.Errorf("got %v, want %v", err2, err)
+		}
+	}
+
+	fw := streamy.NewFrameWriter(io.Discard, streamy.FrameFormatUint16BE)
+	if err := fw.WriteFrame(make([]byte, 70000)); !errors.Is(err, streamy.ErrFrameTooLarge) {
+		t.Errorf("got %v, want %v", err, streamy.ErrFrameTooLarge)
+	}
+	fw = streamy.NewFrameWriter(io.Discard, streamy.FrameFormatUvarint)
+	fw.SetMaxFrameSize(2)
+	if err := fw.WriteFrame([]byte("foo")); !errors.Is(err, streamy.ErrFrameTooLarge) {
+		t.Errorf("got %v, want %v", err, streamy.ErrFrameTooLarge)
+	}
+	fw, _ = streamy.NewDelimitedFrameWriter(io.Discard, []byte("\n"))
+	if err := fw.WriteFrame([]byte("foo\n")); err == nil {
+		t.Errorf("got nil, want error")
+	}
+	for _, delim := range [][]byte{nil, {}} {
+		if _, err := streamy.NewDelimitedFrameWriter(io.Discard, delim); err == nil {
+			t.Errorf("got nil, want error")
+		}
+		if _, err := streamy.NewDelimitedFrameReader(bytes.NewBufferString("foo"), delim); err == nil {
+			t.Errorf("got nil, want error")
+		}
+	}
+}
+
+func BenchmarkFrame(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		streamy.NewFrameWriter(&buf, streamy.FrameFormatUvarint).WriteFrame([]byte("foo bar baz"))
+		streamy.NewFrameReader(&buf, streamy.FrameFormatUvarint).ReadFrame()
+	}
+}
+
+func BenchmarkFrameDelimited(b *testing.B) {
+	content := strings.Repeat("foo\n", 100000)
+	for i := 0; i < b.N; i++ {
+		fr, _ := streamy.NewDelimitedFrameReader(strings.NewReader(content), []byte("\n"))
+		for {
+			if _, err := fr.ReadFrame(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func newLineFrameReader(content string) *streamy.FrameReader {
+	fr, _ := streamy.NewDelimitedFrameReader(bytes.NewBufferString(content), []byte("\n"))
+	return fr
+}
@@ -0,0 +1,135 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrChecksumMismatch means that a computed checksum doesn't match the expected one.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// NewHashingReader returns a HashingReader that computes the given hashes of what it reads from the given reader.
+func NewHashingReader(r io.Reader, hashes ...hash.Hash) *HashingReader {
+	return &HashingReader{r: r, hashes: hashes}
+}
+
+// HashingReader implements the io.Reader interface for computing hashes in one pass.
+type HashingReader struct {
+	r      io.Reader
+	hashes []hash.Hash
+}
+
+// Read implements the io.Reader interface.
+func (hr *HashingReader) Read(p []byte) (n int, err error) {
+	n, err = hr.r.Read(p)
+	if n > 0 {
+		writeHashes(hr.hashes, p[:n])
+	}
+	return n, err
+}
+
+// Sums returns the checksums of the hashes in the given order.
+func (hr *HashingReader) Sums() [][]byte {
+	return sumHashes(hr.hashes)
+}
+
+// NewHashingWriter returns a HashingWriter that computes the given hashes of what it writes to the given writer.
+func NewHashingWriter(w io.Writer, hashes ...hash.Hash) *HashingWriter {
+	return &HashingWriter{w: w, hashes: hashes}
+}
+
+// HashingWriter implements the io.Writer interface for computing hashes in one pass.
+type HashingWriter struct {
+	w      io.Writer
+	hashes []hash.Hash
+}
+
+// Write implements the io.Writer interface.
+func (hw *HashingWriter) Write(p []byte) (n int, err error) {
+	n, err = hw.w.Write(p)
+	if n > 0 {
+		writeHashes(hw.hashes, p[:n])
+	}
+	return n, err
+}
+
+// Sums returns the checksums of the hashes in the given order.
+func (hw *HashingWriter) Sums() [][]byte {
+	return sumHashes(hw.hashes)
+}
+
+// NewVerifyingReader returns a VerifyingReader that verifies what it reads from the given reader
+// by the given hash and the expected checksum (hex or base64 encoded).
+func NewVerifyingReader(r io.Reader, h hash.Hash, expected string) (*VerifyingReader, error) {
+	want, err := decodeChecksum(expected, h.Size())
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyingReader{r: r, h: h, want: want}, nil
+}
+
+// VerifyingReader implements the io.Reader interface for verifying checksums.
+// It returns an error that wraps ErrChecksumMismatch at EOF if the checksum doesn't match.
+type VerifyingReader struct {
+	r    io.Reader
+	h    hash.Hash
+	want []byte
+	err  error
+}
+
+// Read implements the io.Reader interface.
+func (vr *VerifyingReader) Read(p []byte) (n int, err error) {
+	if vr.err != nil {
+		return 0, vr.err
+	}
+	n, err = vr.r.Read(p)
+	if n > 0 {
+		vr.h.Write(p[:n])
+	}
+	if err == io.EOF {
+		if sum := vr.h.Sum(nil); !bytes.Equal(sum, vr.want) {
+			err = fmt.Errorf("%w: got %x, want %x", ErrChecksumMismatch, sum, vr.want)
+		}
+		vr.err = err
+	}
+	return n, err
+}
+
+// writeHashes writes the given bytes to the given hashes.
+func writeHashes(hashes []hash.Hash, p []byte) {
+	for _, h := range hashes {
+		h.Write(p) // It never returns an error.
+	}
+}
+
+// sumHashes returns the checksums of the given hashes.
+func sumHashes(hashes []hash.Hash) [][]byte {
+	sums := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		sums[i] = h.Sum(nil)
+	}
+	return sums
+}
+
+// decodeChecksum decodes the given hex or base64 encoded checksum of the given size.
+func decodeChecksum(s string, size int) ([]byte, error) {
+	if len(s) == hex.EncodedLen(size) {
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == size {
+			return b, nil
+		}
+	}
+	return nil, errors.New("invalid checksum")
+}
@@ -0,0 +1,133 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestHashingReader(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		hashes []hash.Hash
+		sums   []string
+	}{
+		{
+			reader: bytes.NewBufferString("foo bar baz"),
+			hashes: []hash.Hash{crc32.NewIEEE(), md5.New(), sha256.New()},
+			sums: []string{
+				"f262de61",
+				"ab07acbb1e496801937adfa772424bf7",
+				"dbd318c1c462aee872f41109a4dfd3048871a03dedd0fe0e757ced57dad6f2d7",
+			},
+		},
+		{
+			reader: bytes.NewBufferString(""),
+			hashes: []hash.Hash{md5.New()},
+			sums:   []string{"d41d8cd98f00b204e9800998ecf8427e"},
+		},
+		{
+			reader: bytes.NewBufferString("foo"),
+		},
+	}
+	for _, v := range table {
+		hr := streamy.NewHashingReader(v.reader, v.hashes...)
+		if _, err := io.Copy(io.Discard, hr); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		sums := hr.Sums()
+		if len(sums) != len(v.sums) {
+			t.Errorf("got %v, want %v", len(sums), len(v.sums))
+			continue
+		}
+		for i, sum := range sums {
+			if s := hex.EncodeToString(sum); s != v.sums[i] {
+				t.Errorf("got %v, want %v", s, v.sums[i])
+			}
+		}
+	}
+}
+
+func TestHashingWriter(t *testing.T) {
+	table := []struct {
+		content string
+		hashes  []hash.Hash
+		sums    []string
+	}{
+		{
+			content: "foo bar baz",
+			hashes:  []hash.Hash{sha1.New(), sha512.New()},
+			sums: []string{
+				"c7567e8b39e2428e38bf9c9226ac68de4c67dc39",
+				"bce50343a56f01dc7cf2d4c82127be4fff3a83ddb8b783b1a28fb6574637ceb71ef594b1f03a8e9b7d754341831292bcad1a3cb8a12fd2ded7a57b1b173b3bf7",
+			},
+		},
+	}
+	for _, v := range table {
+		var buf bytes.Buffer
+		hw := streamy.NewHashingWriter(&buf, v.hashes...)
+		if _, err := io.WriteString(hw, v.content); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if buf.String() != v.content {
+			t.Errorf("got %v, want %v", buf.String(), v.content)
+		}
+		for i, sum := range hw.Sums() {
+			if s := hex.EncodeToString(sum); s != v.sums[i] {
+				t.Errorf("got %v, want %v", s, v.sums[i])
+			}
+		}
+	}
+}
+
+func TestVerifyingReader(t *testing.T) {
+	table := []struct {
+		reader   io.Reader
+		hash     hash.Hash
+		expected string
+		newErr   bool
+		err      error
+	}{
+		{bytes.NewBufferString("foo bar baz"), sha256.New(), "dbd318c1c462aee872f41109a4dfd3048871a03dedd0fe0e757ced57dad6f2d7", false, nil},
+		{bytes.NewBufferString("foo bar baz"), sha256.New(), "29MYwcRiruhy9BEJpN/TBIhxoD3t0P4OdXztV9rW8tc=", false, nil},
+		{bytes.NewBufferString("foo bar baz"), sha256.New(), "29MYwcRiruhy9BEJpN_TBIhxoD3t0P4OdXztV9rW8tc", false, nil},
+		{bytes.NewBufferString("foo bar baz"), crc32.NewIEEE(), "f262de61", false, nil},
+		{bytes.NewBufferString("foo bar qux"), sha256.New(), "dbd318c1c462aee872f41109a4dfd3048871a03dedd0fe0e757ced57dad6f2d7", false, streamy.ErrChecksumMismatch},
+		{bytes.NewBufferString("foo bar baz"), md5.New(), "dbd318c1c462aee872f41109a4dfd3048871a03dedd0fe0e757ced57dad6f2d7", true, nil},
+		{bytes.NewBufferString("foo bar baz"), md5.New(), "foo", true, nil},
+	}
+	for _, v := range table {
+		vr, err := streamy.NewVerifyingReader(v.reader, v.hash, v.expected)
+		if v.newErr {
+			if err == nil {
+				t.Errorf("got nil, want error")
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("got %v, want nil", err)
+			continue
+		}
+		_, err = io.Copy(io.Discard, vr)
+		if !errors.Is(err, v.err) {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+	}
+}
+
+func BenchmarkHashingReader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		io.Copy(io.Discard, streamy.NewHashingReader(bytes.NewBufferString("foo bar baz"), md5.New(), sha256.New()))
+	}
+}
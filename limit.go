@@ -0,0 +1,110 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooLarge means that a stream exceeds its size limit.
+var ErrTooLarge = errors.New("too large")
+
+// NewLimitedReader returns a LimitedReader that reads from the given reader up to the given limit.
+// Unlike io.LimitReader, it returns ErrTooLarge instead of io.EOF once the limit is exceeded.
+func NewLimitedReader(r io.Reader, limit BinaryUnit) *LimitedReader {
+	return &LimitedReader{r: r, limit: int64(limit), peek: true}
+}
+
+// LimitedReader implements the io.Reader interface for reading strictly up to a limit.
+// By default it peeks one extra byte at the limit for deciding whether the stream is complete or too large.
+type LimitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+	peek  bool
+	err   error
+}
+
+// DisablePeek disables peeking one extra byte at the limit. In that case reaching the limit is treated as
+// exceeding it. It's useful when an extra read might block (i.e. interactive streams).
+func (lr *LimitedReader) DisablePeek() {
+	lr.peek = false
+}
+
+// Read implements the io.Reader interface.
+func (lr *LimitedReader) Read(p []byte) (n int, err error) {
+	if lr.err != nil {
+		return 0, lr.err
+	} else if len(p) == 0 {
+		return 0, nil
+	}
+
+	rest := lr.limit - lr.read
+	if rest <= 0 {
+		if !lr.peek {
+			lr.err = ErrTooLarge
+			return 0, lr.err
+		}
+		// Peek one extra byte for deciding
+		var b [1]byte
+		for n == 0 && err == nil {
+			n, err = lr.r.Read(b[:])
+		}
+		if n > 0 {
+			err = ErrTooLarge
+		}
+		lr.err = err
+		return 0, err
+	}
+
+	// Read up to the limit (plus one byte for peeking)
+	size := rest
+	if lr.peek {
+		size++
+	}
+	if int64(len(p)) > size {
+		p = p[:size]
+	}
+	n, err = lr.r.Read(p)
+	if int64(n) > rest {
+		n, err = int(rest), ErrTooLarge
+	}
+	lr.read += int64(n)
+	if err != nil {
+		lr.err = err
+	}
+	return n, err
+}
+
+// NewLimitedWriter returns a LimitedWriter that writes to the given writer up to the given limit.
+func NewLimitedWriter(w io.Writer, limit BinaryUnit) *LimitedWriter {
+	return &LimitedWriter{w: w, limit: int64(limit)}
+}
+
+// LimitedWriter implements the io.Writer interface for writing strictly up to a limit.
+// It writes the bytes up to the limit and returns ErrTooLarge for the rest.
+type LimitedWriter struct {
+	w       io.Writer
+	limit   int64
+	written int64
+}
+
+// Write implements the io.Writer interface.
+func (lw *LimitedWriter) Write(p []byte) (n int, err error) {
+	rest := lw.limit - lw.written
+	if rest <= 0 && len(p) > 0 {
+		return 0, ErrTooLarge
+	}
+	over := int64(len(p)) > rest
+	if over {
+		p = p[:rest]
+	}
+	n, err = lw.w.Write(p)
+	lw.written += int64(n)
+	if err == nil && over {
+		err = ErrTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestLimitedReader(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		limit  streamy.BinaryUnit
+		noPeek bool
+		want   string
+		err    error
+	}{
+		{bytes.NewBufferString("foo bar baz"), 11, false, "foo bar baz", nil},
+		{bytes.NewBufferString("foo bar baz"), 20, false, "foo bar baz", nil},
+		{bytes.NewBufferString("foo bar baz"), 10, false, "foo bar ba", streamy.ErrTooLarge},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 11, false, "foo bar baz", nil},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 3, false, "foo", streamy.ErrTooLarge},
+		{bytes.NewBufferString("foo bar baz"), 11, true, "foo bar baz", streamy.ErrTooLarge},
+		{bytes.NewBufferString("foo bar baz"), 12, true, "foo bar baz", nil},
+		{bytes.NewBufferString("foo bar baz"), 3, true, "foo", streamy.ErrTooLarge},
+		{bytes.NewBufferString(""), 0, false, "", nil},
+	}
+	for _, v := range table {
+		lr := streamy.NewLimitedReader(v.reader, v.limit)
+		if v.noPeek {
+			lr.DisablePeek()
+		}
+		b, err := io.ReadAll(lr)
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if string(b) != v.want {
+			t.Errorf("got %v, want %v", string(b), v.want)
+		}
+	}
+}
+
+func TestLimitedWriter(t *testing.T) {
+	table := []struct {
+		writes []string
+		limit  streamy.BinaryUnit
+		want   string
+		err    error
+	}{
+		{[]string{"foo", " bar", " baz"}, 11, "foo bar baz", nil},
+		{[]string{"foo", " bar", " baz"}, 9, "foo bar b", streamy.ErrTooLarge},
+		{[]string{"foo", " bar", " baz"}, 7, "foo bar", streamy.ErrTooLarge},
+		{[]string{"foo bar baz"}, 0, "", streamy.ErrTooLarge},
+	}
+	for _, v := range table {
+		var buf bytes.Buffer
+		lw := streamy.NewLimitedWriter(&buf, v.limit)
+		var err error
+		for _, s := range v.writes {
+			if _, err = io.WriteString(lw, s); err != nil {
+				break
+			}
+		}
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if buf.String() != v.want {
+			t.Errorf("got %v, want %v", buf.String(), v.want)
+		}
+	}
+}
+
+func BenchmarkLimitedReader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		io.Copy(io.Discard, streamy.NewLimitedReader(bytes.NewBufferString("foo bar baz"), streamy.KiB))
+	}
+}
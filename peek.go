@@ -0,0 +1,192 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrPeekBufferFull means that a peek request exceeds the buffer size of a PeekReader.
+	ErrPeekBufferFull = errors.New("peek buffer full")
+	// ErrPeekInvalidMark means that there is no valid mark to reset to (i.e. the buffer size was exceeded).
+	ErrPeekInvalidMark = errors.New("invalid mark")
+)
+
+// NewPeekReader returns a PeekReader that reads from the given reader and buffers up to the given size.
+// If the size is 0 then the default size (4096 bytes) is used.
+func NewPeekReader(r io.Reader, size BinaryUnit) *PeekReader {
+	if size <= 0 {
+		size = 4096
+	}
+	return &PeekReader{r: r, limit: int(size), mark: -1}
+}
+
+// PeekReader implements the io.Reader interface with peek and rewind support.
+// It's useful for inspecting non-seekable streams (see HaveSeeker) before processing them.
+type PeekReader struct {
+	r     io.Reader
+	buf   []byte // buf holds the bytes from the mark (or the read offset) onwards.
+	off   int    // off is the read offset in buf.
+	mark  int    // mark is the marked offset in buf or -1 if there is no mark.
+	limit int
+	err   error
+}
+
+// Read implements the io.Reader interface.
+func (pr *PeekReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Serve the buffered bytes first
+	if pr.off < len(pr.buf) {
+		n = copy(p, pr.buf[pr.off:])
+		pr.off += n
+		if pr.mark < 0 && pr.off == len(pr.buf) {
+			pr.buf = pr.buf[:0]
+			pr.off = 0
+		}
+		return n, nil
+	}
+	if pr.err != nil {
+		return 0, pr.err
+	}
+
+	// Read directly if there is no mark to keep
+	if pr.mark < 0 {
+		return pr.r.Read(p)
+	}
+
+	// Keep the bytes for the mark if there is space, otherwise invalidate it
+	space := pr.limit - len(pr.buf)
+	if space <= 0 {
+		pr.mark = -1
+		pr.compact()
+		return pr.r.Read(p)
+	}
+	if len(p) > space {
+		p = p[:space]
+	}
+	n, err = pr.r.Read(p)
+	pr.buf = append(pr.buf, p[:n]...)
+	pr.off += n
+	if err != nil {
+		pr.err = err
+	}
+	return n, err
+}
+
+// Peek returns the next n bytes without advancing the reader.
+// If it returns fewer than n bytes then it also returns an error explaining why the read is short.
+// Note that the returned byte slice is only valid until the next read call.
+func (pr *PeekReader) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errors.New("negative count")
+	} else if n > pr.limit {
+		return nil, ErrPeekBufferFull
+	}
+
+	// Drop the mark if the request doesn't fit into the buffer otherwise
+	if pr.off+n > pr.limit {
+		pr.mark = -1
+		pr.compact()
+	}
+
+	// Fill the buffer
+	if err := pr.fill(pr.off + n); err != nil && len(pr.buf)-pr.off < n {
+		return pr.buf[pr.off:], err
+	}
+	return pr.buf[pr.off : pr.off+n], nil
+}
+
+// Buffered returns the number of bytes that can be read from the buffer.
+func (pr *PeekReader) Buffered() int {
+	return len(pr.buf) - pr.off
+}
+
+// Mark marks the current position so it can be returned by calling the Reset method.
+// The mark stays valid as long as the number of bytes read after it doesn't exceed the buffer size.
+func (pr *PeekReader) Mark() {
+	pr.mark = -1
+	pr.compact()
+	pr.mark = 0
+}
+
+// Reset rewinds the reader to the last marked position.
+func (pr *PeekReader) Reset() error {
+	if pr.mark < 0 {
+		return ErrPeekInvalidMark
+	}
+	pr.off = pr.mark
+	return nil
+}
+
+// Reader returns an io.Reader that yields the unread bytes (including the buffered ones) and
+// the rest of the underlying reader. It's useful for handing back the full stream after peeking.
+// Note that the PeekReader should not be used after calling this method.
+func (pr *PeekReader) Reader() io.Reader {
+	buf := bytes.NewReader(pr.buf[pr.off:])
+	if pr.err != nil {
+		return io.MultiReader(buf, &errReader{err: pr.err})
+	}
+	return io.MultiReader(buf, pr.r)
+}
+
+// fill reads from the underlying reader until the buffer has the given number of bytes or an error occurs.
+func (pr *PeekReader) fill(size int) error {
+	if size > cap(pr.buf) {
+		// Grow the buffer as needed but never beyond the limit.
+		c := 2 * cap(pr.buf)
+		if c < size {
+			c = size
+		}
+		if c < 512 {
+			c = 512
+		}
+		if c > pr.limit {
+			c = pr.limit
+		}
+		buf := make([]byte, len(pr.buf), c)
+		copy(buf, pr.buf)
+		pr.buf = buf
+	}
+	for len(pr.buf) < size && pr.err == nil {
+		n, err := pr.r.Read(pr.buf[len(pr.buf):cap(pr.buf)])
+		pr.buf = pr.buf[:len(pr.buf)+n]
+		if err != nil {
+			pr.err = err
+		}
+	}
+	return pr.err
+}
+
+// compact drops the bytes that are not needed anymore (i.e. read bytes before the mark).
+func (pr *PeekReader) compact() {
+	start := pr.off
+	if pr.mark >= 0 {
+		start = pr.mark
+	}
+	if start == 0 {
+		return
+	}
+	n := copy(pr.buf, pr.buf[start:])
+	pr.buf = pr.buf[:n]
+	pr.off -= start
+	if pr.mark >= 0 {
+		pr.mark = 0
+	}
+}
+
+// errReader implements an io.Reader that always returns the given error.
+type errReader struct {
+	err error
+}
+
+// Read implements the io.Reader interface.
+func (er *errReader) Read(p []byte) (n int, err error) {
+	return 0, er.err
+}
@@ -0,0 +1,106 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestPeekReader(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		size   streamy.BinaryUnit
+		peek   int
+		want   string
+		err    error
+	}{
+		{bytes.NewBufferString("foo bar baz"), 0, 3, "foo", nil},
+		{bytes.NewBufferString("foo bar baz"), 4, 4, "foo ", nil},
+		{bytes.NewBufferString("foo bar baz"), 4, 5, "", streamy.ErrPeekBufferFull},
+		{bytes.NewBufferString("foo"), 0, 5, "foo", io.EOF},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 0, 7, "foo bar", nil},
+	}
+	for _, v := range table {
+		pr := streamy.NewPeekReader(v.reader, v.size)
+		b, err := pr.Peek(v.peek)
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if string(b) != v.want {
+			t.Errorf("got %v, want %v", string(b), v.want)
+		}
+		all, err := io.ReadAll(pr)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.HasPrefix(all, []byte(v.want)) {
+			t.Errorf("got %v, want prefix %v", string(all), v.want)
+		}
+	}
+}
+
+func TestPeekReaderMark(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		size   streamy.BinaryUnit
+		read   int
+		err    error
+		want   string
+	}{
+		{bytes.NewBufferString("foo bar baz"), 0, 7, nil, "foo bar baz"},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 4, 4, nil, "foo bar baz"},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 4, 5, streamy.ErrPeekInvalidMark, "ar baz"},
+	}
+	for _, v := range table {
+		pr := streamy.NewPeekReader(v.reader, v.size)
+		pr.Mark()
+		if _, err := io.ReadFull(pr, make([]byte, v.read)); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if err := pr.Reset(); err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		}
+		all, err := io.ReadAll(pr)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(all) != v.want {
+			t.Errorf("got %v, want %v", string(all), v.want)
+		}
+	}
+}
+
+func TestPeekReaderReader(t *testing.T) {
+	table := []struct {
+		reader io.Reader
+		peek   int
+		read   int
+		want   string
+	}{
+		{bytes.NewBufferString("foo bar baz"), 3, 0, "foo bar baz"},
+		{bytes.NewBufferString("foo bar baz"), 7, 4, "bar baz"},
+		{bytes.NewBufferString("foo"), 10, 0, "foo"},
+	}
+	for _, v := range table {
+		pr := streamy.NewPeekReader(streamy.ReaderOnly(v.reader), 0)
+		pr.Peek(v.peek)
+		io.ReadFull(pr, make([]byte, v.read))
+		all, err := io.ReadAll(pr.Reader())
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(all) != v.want {
+			t.Errorf("got %v, want %v", string(all), v.want)
+		}
+	}
+}
+
+func BenchmarkPeekReader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pr := streamy.NewPeekReader(bytes.NewBufferString("foo bar baz"), 0)
+		pr.Peek(3)
+		io.Copy(io.Discard, pr)
+	}
+}
@@ -5,6 +5,8 @@ package streamy
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"math"
 	"sync"
 	"time"
@@ -13,6 +15,27 @@ import (
 // ErrProgressStopped means that a progress stopped (i.e. by calling Stop method).
 var ErrProgressStopped = errors.New("stopped")
 
+var (
+	// ErrProgressQuotaExceeded means that a progress stopped since the byte quota is exceeded.
+	ErrProgressQuotaExceeded = fmt.Errorf("quota exceeded: %w", ErrProgressStopped)
+	// ErrProgressDeadlineExceeded means that a progress stopped since the deadline is passed.
+	ErrProgressDeadlineExceeded = fmt.Errorf("deadline exceeded: %w", ErrProgressStopped)
+	// ErrProgressTooSlow means that a progress stopped since the throughput is below the minimum.
+	ErrProgressTooSlow = fmt.Errorf("throughput too low: %w", ErrProgressStopped)
+)
+
+// NewProgressFrom returns a new Progress with the total size inferred from the given source
+// (i.e. *os.File, *bytes.Reader, io.Seeker or values with a ContentLength() int64 method).
+// The total size is left unknown if it can't be inferred (i.e. pipes). For an HTTP body, pass the
+// body and set the content length explicitly (i.e. NewProgressFrom(resp.Body) and SetTotalSize).
+func NewProgressFrom(src interface{}) *Progress {
+	progress := &Progress{}
+	if size, ok := sizeOf(src); ok {
+		progress.SetTotalSize(size, Byte)
+	}
+	return progress
+}
+
 // Progress implements the io.Writer interface for tracking bytes.
 type Progress struct {
 	bytesWritten     int64
@@ -28,19 +51,38 @@ type Progress struct {
 	controlsStopCh   chan struct{}
 	rwMu             sync.RWMutex
 	stopped          bool
+	stopErr          error
+	quotaBytes       int64
+	deadline         time.Time
+	minBytesPerSec   int64
+	minGrace         time.Duration
+	startedAt        time.Time
 }
 
 // Write implements the io.Writer interface.
 func (progress *Progress) Write(p []byte) (n int, err error) {
-	// Init vars
-	n = len(p)
-	ni := int64(n)
+	if err := progress.update(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// update updates the progress by the given number of bytes without the bytes themselves.
+// It checks the limits and the controls the same way as the Write method.
+func (progress *Progress) update(ni int64) error {
+	// Check limits
+	counted, err := progress.checkLimits(ni)
+	if err != nil {
+		return err
+	}
 
 	progress.rwMu.RLock()
 	defer progress.rwMu.RUnlock()
 
 	// Update written bytes
-	progress.bytesWritten += ni
+	if !counted {
+		progress.bytesWritten += ni
+	}
 
 	// Update stats
 	if progress.statsEnabled {
@@ -65,7 +107,7 @@ func (progress *Progress) Write(p []byte) (n int, err error) {
 		case _, ok := <-progress.controlsStopCh:
 			if !ok {
 				progress.stopped = true
-				return 0, ErrProgressStopped
+				return ErrProgressStopped
 			}
 		}
 	}
@@ -76,7 +118,7 @@ func (progress *Progress) Write(p []byte) (n int, err error) {
 		progress.statsTo = time.Now().UnixNano()
 	}
 
-	return n, nil
+	return nil
 }
 
 // BytesWritten returns the number of bytes written.
@@ -94,6 +136,100 @@ func (progress *Progress) TotalBytes() int64 {
 	return progress.totalBytes
 }
 
+// SetQuota sets the byte quota by the given size and binary unit.
+// The progress writer stops by ErrProgressQuotaExceeded when a write exceeds the quota.
+func (progress *Progress) SetQuota(size int64, unit BinaryUnit) {
+	progress.rwMu.Lock()
+	defer progress.rwMu.Unlock()
+	progress.quotaBytes = size * int64(unit)
+}
+
+// SetDeadline sets the wall-clock deadline.
+// The progress writer stops by ErrProgressDeadlineExceeded when a write happens after the deadline.
+func (progress *Progress) SetDeadline(t time.Time) {
+	progress.rwMu.Lock()
+	defer progress.rwMu.Unlock()
+	progress.deadline = t
+}
+
+// SetMinThroughput sets the minimum throughput (bytes per second) that is checked after the given grace period
+// since the first write. The progress writer stops by ErrProgressTooSlow when the average throughput is below it.
+func (progress *Progress) SetMinThroughput(bytesPerSecond int64, grace time.Duration) {
+	progress.rwMu.Lock()
+	defer progress.rwMu.Unlock()
+	progress.minBytesPerSec = bytesPerSecond
+	progress.minGrace = grace
+}
+
+// Err returns the error that stopped the progress writer by a limit (see SetQuota, SetDeadline and SetMinThroughput).
+func (progress *Progress) Err() error {
+	progress.rwMu.RLock()
+	defer progress.rwMu.RUnlock()
+	return progress.stopErr
+}
+
+// remaining returns the number of bytes left until the quota is exceeded or -1 if there is no quota.
+func (progress *Progress) remaining() int64 {
+	progress.rwMu.RLock()
+	defer progress.rwMu.RUnlock()
+	if progress.quotaBytes <= 0 {
+		return -1
+	} else if progress.bytesWritten >= progress.quotaBytes {
+		return 0
+	}
+	return progress.quotaBytes - progress.bytesWritten
+}
+
+// checkLimits checks the limits (if any) for the given number of bytes to write and counts the bytes
+// if they are in the limits. Since it changes the limit state, it holds the write lock but only when
+// there is a limit. It returns false if the bytes aren't counted.
+func (progress *Progress) checkLimits(n int64) (bool, error) {
+	progress.rwMu.RLock()
+	limited := progress.stopErr != nil || progress.quotaBytes > 0 || !progress.deadline.IsZero() || progress.minBytesPerSec > 0
+	progress.rwMu.RUnlock()
+	if !limited {
+		return false, nil
+	}
+
+	progress.rwMu.Lock()
+	defer progress.rwMu.Unlock()
+	if progress.stopErr != nil {
+		return false, progress.stopErr
+	}
+	if err := progress.limitErr(n); err != nil {
+		progress.stopped = true
+		progress.stopErr = err
+		return false, err
+	}
+	progress.bytesWritten += n
+	return true, nil
+}
+
+// limitErr returns the error of the limit that the given number of bytes to write exceeds (if any).
+// The caller must hold the write lock.
+func (progress *Progress) limitErr(n int64) error {
+	if progress.quotaBytes > 0 && progress.bytesWritten+n > progress.quotaBytes {
+		return ErrProgressQuotaExceeded
+	}
+	if progress.deadline.IsZero() && progress.minBytesPerSec <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if !progress.deadline.IsZero() && now.After(progress.deadline) {
+		return ErrProgressDeadlineExceeded
+	}
+	if progress.minBytesPerSec > 0 {
+		if progress.startedAt.IsZero() {
+			progress.startedAt = now
+		} else if elapsed := now.Sub(progress.startedAt); elapsed > progress.minGrace {
+			if float64(progress.bytesWritten)/elapsed.Seconds() < float64(progress.minBytesPerSec) {
+				return ErrProgressTooSlow
+			}
+		}
+	}
+	return nil
+}
+
 // EnableStats enables the progress stats.
 func (progress *Progress) EnableStats(mode ProgressStatsMode) error {
 	progress.rwMu.Lock()
@@ -164,6 +300,9 @@ func (progress *Progress) Stats() ProgressStats {
 		TotalBytes:   progress.totalBytes,
 		Took:         time.Duration(progress.statsTo - progress.statsFrom),
 	}
+	if progress.statsTo > 0 {
+		stats.Idle = time.Duration(time.Now().UnixNano() - progress.statsTo)
+	}
 
 	// Calculate the percentage
 	// Note that totalBytes is given by the user and it can be 0 (see progress.SetTotalSize).
@@ -211,6 +350,38 @@ func (progress *Progress) Stats() ProgressStats {
 	return stats
 }
 
+// sizeOf returns the number of bytes that can be read from the given source if it's known.
+func sizeOf(src interface{}) (int64, bool) {
+	if size, ok := contentLength(src); ok {
+		return size, size >= 0
+	}
+	if HaveSeeker(src) {
+		// Seek to the end and back to the current offset.
+		s := src.(io.Seeker)
+		if cur, err := s.Seek(0, io.SeekCurrent); err == nil {
+			end, err := s.Seek(0, io.SeekEnd)
+			if _, serr := s.Seek(cur, io.SeekStart); err == nil && serr == nil && end >= cur {
+				return end - cur, true
+			}
+		}
+	}
+	if r, ok := src.(io.Reader); ok {
+		if s := Describe(r); s.Size >= 0 {
+			return s.Size, true
+		}
+	}
+	return 0, false
+}
+
+// contentLength returns the content length of the given source if it has a ContentLength method.
+// Note that a negative value means unknown.
+func contentLength(src interface{}) (int64, bool) {
+	if v, ok := src.(interface{ ContentLength() int64 }); ok {
+		return v.ContentLength(), true
+	}
+	return 0, false
+}
+
 // ProgressStats represents the progress stats.
 type ProgressStats struct {
 	TotalBytes     int64
@@ -219,6 +390,7 @@ type ProgressStats struct {
 	Took           time.Duration
 	Remaining      time.Duration
 	Percentage     int
+	Idle           time.Duration // Idle is the time passed since the last write.
 }
 
 // ProgressStatsMode represents a progress stats mode.
@@ -5,7 +5,11 @@ package streamy_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -43,6 +47,46 @@ func TestProgress(t *testing.T) {
 	}
 }
 
+func TestNewProgressFrom(t *testing.T) {
+	f, _ := os.Open("progress_test.go")
+	defer f.Close()
+	fi, _ := f.Stat()
+
+	fp, _ := os.Open("progress_test.go")
+	defer fp.Close()
+	fp.Seek(10, io.SeekStart)
+
+	pr, pw, _ := os.Pipe()
+	defer pr.Close()
+	defer pw.Close()
+
+	table := []struct {
+		src        interface{}
+		totalBytes int64
+	}{
+		{f, fi.Size()},
+		{fp, fi.Size() - 10},
+		{bytes.NewReader([]byte("foo")), 3},
+		{bytes.NewBufferString("foo bar"), 7},
+		{strings.NewReader("foo bar baz"), 11},
+		{contentLengther(5), 5},
+		{contentLengther(-1), 0},
+		{struct{ ContentLength int64 }{9}, 0},
+		{pr, 0},
+		{streamy.ReaderOnly(bytes.NewBufferString("foo")), 0},
+		{nil, 0},
+	}
+	for _, v := range table {
+		progress := streamy.NewProgressFrom(v.src)
+		if progress.TotalBytes() != v.totalBytes {
+			t.Errorf("got %v, want %v", progress.TotalBytes(), v.totalBytes)
+		}
+	}
+	if off, _ := fp.Seek(0, io.SeekCurrent); off != 10 {
+		t.Errorf("got %v, want %v", off, 10)
+	}
+}
+
 func TestProgressStats(t *testing.T) {
 	delay := 10 * time.Millisecond
 	table := []struct {
@@ -222,6 +266,107 @@ func TestProgressControls(t *testing.T) {
 	}
 }
 
+func TestProgressLimits(t *testing.T) {
+	delay := 10 * time.Millisecond
+	table := []struct {
+		reader        io.Reader
+		quota         int64
+		deadline      time.Duration
+		minThroughput int64
+		grace         time.Duration
+		written       int64
+		err           error
+	}{
+		{
+			reader:  bytes.NewBufferString("foo bar baz"),
+			quota:   11,
+			written: 11,
+		},
+		{
+			reader:  &slowReader{content: "foo bar baz", delay: delay},
+			quota:   5,
+			written: 5,
+			err:     streamy.ErrProgressQuotaExceeded,
+		},
+		{
+			reader:   &slowReader{content: "foo bar baz", delay: delay},
+			deadline: delay * 3,
+			err:      streamy.ErrProgressDeadlineExceeded,
+		},
+		{
+			reader:   &slowReader{content: "foo bar baz", delay: delay},
+			deadline: time.Minute,
+			written:  11,
+		},
+		{
+			reader:        &slowReader{content: "foo bar baz", delay: delay},
+			minThroughput: 1000,
+			grace:         delay * 3,
+			err:           streamy.ErrProgressTooSlow,
+		},
+		{
+			reader:        &slowReader{content: "foo bar baz", delay: delay},
+			minThroughput: 1,
+			grace:         delay,
+			written:       11,
+		},
+	}
+	for _, v := range table {
+		progress := streamy.Progress{}
+		if v.quota > 0 {
+			progress.SetQuota(v.quota, streamy.Byte)
+		}
+		if v.deadline > 0 {
+			progress.SetDeadline(time.Now().Add(v.deadline))
+		}
+		if v.minThroughput > 0 {
+			progress.SetMinThroughput(v.minThroughput, v.grace)
+		}
+		written, err := io.Copy(io.Discard, io.TeeReader(v.reader, &progress))
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if progress.Err() != v.err {
+			t.Errorf("got %v, want %v", progress.Err(), v.err)
+		} else if v.err != nil && !errors.Is(err, streamy.ErrProgressStopped) {
+			t.Errorf("got %v, want %v", err, streamy.ErrProgressStopped)
+		}
+		if v.err == nil || v.written > 0 {
+			if written != v.written {
+				t.Errorf("got %v, want %v", written, v.written)
+			}
+		} else if written <= 0 || written >= 11 {
+			t.Errorf("got %v, want >0 <11", written)
+		}
+	}
+}
+
+func TestProgressLimitsConcurrent(t *testing.T) {
+	progress := streamy.Progress{}
+	progress.SetQuota(1000, streamy.Byte)
+	progress.SetMinThroughput(1, time.Minute)
+
+	// The quota should be exact even if the writes and the checks are concurrent.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := progress.Write([]byte("foo")); err != nil {
+					return
+				}
+				progress.Err()
+			}
+		}()
+	}
+	wg.Wait()
+	if progress.Err() != streamy.ErrProgressQuotaExceeded {
+		t.Errorf("got %v, want %v", progress.Err(), streamy.ErrProgressQuotaExceeded)
+	} else if progress.BytesWritten() != 999 {
+		t.Errorf("got %v, want %v", progress.BytesWritten(), 999)
+	}
+}
+
 func BenchmarkProgress(b *testing.B) {
 	progress := streamy.Progress{}
 	for i := 0; i < b.N; i++ {
@@ -278,3 +423,11 @@ func (sr *slowReader) Read(p []byte) (n int, err error) {
 	}
 	return 1, nil
 }
+
+// contentLengther implements a source that has a ContentLength method.
+type contentLengther int64
+
+// ContentLength returns the content length.
+func (c contentLengther) ContentLength() int64 {
+	return int64(c)
+}
@@ -0,0 +1,141 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// NewReadAheadReader returns a ReadAheadReader that prefetches up to the given number of buffers of
+// the given size from the given reader on a background goroutine.
+// If the buffers or the size is 0 then the default values (4 buffers of 64 KiB) are used.
+func NewReadAheadReader(r io.Reader, buffers int, size BinaryUnit) *ReadAheadReader {
+	if buffers <= 0 {
+		buffers = 4
+	}
+	if size <= 0 {
+		size = 64 * KiB
+	}
+	rar := &ReadAheadReader{
+		free:   make(chan []byte, buffers),
+		filled: make(chan readAheadBuffer, buffers),
+		stop:   make(chan struct{}),
+	}
+	for i := 0; i < buffers; i++ {
+		rar.free <- make([]byte, int(size))
+	}
+	go rar.prefetch(r)
+	return rar
+}
+
+// ReadAheadReader implements the io.ReadCloser interface for prefetching a reader in the background.
+// The errors of the underlying reader are returned after the bytes that are read before them.
+type ReadAheadReader struct {
+	free     chan []byte
+	filled   chan readAheadBuffer
+	stop     chan struct{}
+	stopOnce sync.Once
+	cur      readAheadBuffer
+	off      int
+	err      error
+	waits    int64
+}
+
+// readAheadBuffer represents a prefetched buffer.
+type readAheadBuffer struct {
+	b   []byte
+	err error
+}
+
+// Read implements the io.Reader interface.
+func (rar *ReadAheadReader) Read(p []byte) (n int, err error) {
+	if rar.err != nil {
+		return 0, rar.err
+	}
+	select {
+	case <-rar.stop:
+		rar.err = os.ErrClosed
+		return 0, rar.err
+	default:
+	}
+
+	// Serve the current buffer first
+	if rar.off < len(rar.cur.b) {
+		n = copy(p, rar.cur.b[rar.off:])
+		rar.off += n
+		return n, nil
+	}
+	if rar.cur.err != nil {
+		rar.err = rar.cur.err
+		return 0, rar.err
+	}
+
+	// Recycle the current buffer and get the next one
+	if rar.cur.b != nil {
+		rar.free <- rar.cur.b[:cap(rar.cur.b)]
+		rar.cur = readAheadBuffer{}
+	}
+	var buf readAheadBuffer
+	var ok bool
+	select {
+	case buf, ok = <-rar.filled:
+	default:
+		// The consumer is faster than the prefetching.
+		atomic.AddInt64(&rar.waits, 1)
+		select {
+		case buf, ok = <-rar.filled:
+		case <-rar.stop:
+		}
+	}
+	if !ok {
+		rar.err = os.ErrClosed
+		return 0, rar.err
+	}
+	rar.cur, rar.off = buf, 0
+	n = copy(p, buf.b)
+	rar.off = n
+	if n == 0 && buf.err != nil {
+		rar.err = buf.err
+		return 0, rar.err
+	}
+	return n, nil
+}
+
+// Waits returns the number of read calls that had to wait for the prefetching.
+func (rar *ReadAheadReader) Waits() int64 {
+	return atomic.LoadInt64(&rar.waits)
+}
+
+// Close implements the io.Closer interface. It stops the prefetching and unblocks a waiting Read call
+// (i.e. from another goroutine). Note that it doesn't close the underlying reader.
+func (rar *ReadAheadReader) Close() error {
+	rar.stopOnce.Do(func() {
+		close(rar.stop)
+	})
+	return nil
+}
+
+// prefetch reads from the given reader into the free buffers until an error occurs or it's stopped.
+func (rar *ReadAheadReader) prefetch(r io.Reader) {
+	for {
+		var b []byte
+		select {
+		case b = <-rar.free:
+		case <-rar.stop:
+			return
+		}
+		n, err := r.Read(b)
+		select {
+		case rar.filled <- readAheadBuffer{b: b[:n], err: err}:
+		case <-rar.stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
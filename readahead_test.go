@@ -0,0 +1,133 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestReadAheadReader(t *testing.T) {
+	errFoo := errors.New("foo")
+	content := strings.Repeat("foo bar baz ", 1000)
+	table := []struct {
+		reader  io.Reader
+		buffers int
+		size    streamy.BinaryUnit
+		want    string
+		err     error
+	}{
+		{bytes.NewBufferString(content), 0, 0, content, io.EOF},
+		{bytes.NewBufferString(content), 2, 16, content, io.EOF},
+		{iotest.OneByteReader(bytes.NewBufferString("foo bar baz")), 1, 1, "foo bar baz", io.EOF},
+		{io.MultiReader(bytes.NewBufferString("foo bar baz"), iotest.ErrReader(errFoo)), 2, 4, "foo bar baz", errFoo},
+		{iotest.DataErrReader(bytes.NewBufferString("foo bar baz")), 2, 4, "foo bar baz", io.EOF},
+	}
+	for _, v := range table {
+		rar := streamy.NewReadAheadReader(v.reader, v.buffers, v.size)
+		var buf bytes.Buffer
+		var err error
+		p := make([]byte, 7)
+		for {
+			var n int
+			n, err = rar.Read(p)
+			buf.Write(p[:n])
+			if err != nil {
+				break
+			}
+		}
+		if err != v.err {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if buf.String() != v.want {
+			t.Errorf("got %v bytes, want %v bytes", buf.Len(), len(v.want))
+		}
+		rar.Close()
+	}
+}
+
+func TestReadAheadReaderClose(t *testing.T) {
+	rar := streamy.NewReadAheadReader(&slowReader{content: "foo bar baz", delay: 10 * time.Millisecond}, 2, 4)
+	p := make([]byte, 4)
+	if _, err := rar.Read(p); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	if err := rar.Close(); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	if _, err := rar.Read(p); err != os.ErrClosed {
+		t.Errorf("got %v, want %v", err, os.ErrClosed)
+	}
+	if rar.Waits() < 1 {
+		t.Errorf("got %v, want >=1", rar.Waits())
+	}
+
+	// Close should unblock a waiting read from another goroutine.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	rar = streamy.NewReadAheadReader(pr, 2, 4)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := rar.Read(p)
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	rar.Close()
+	select {
+	case err := <-errCh:
+		if err != os.ErrClosed {
+			t.Errorf("got %v, want %v", err, os.ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("got blocked, want %v", os.ErrClosed)
+	}
+}
+
+func BenchmarkReadAheadReader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rar := streamy.NewReadAheadReader(&latencyReader{n: 20, delay: 100 * time.Microsecond}, 4, 4*streamy.KiB)
+		consumeSlowly(rar, 100*time.Microsecond)
+		rar.Close()
+	}
+}
+
+func BenchmarkReadAheadReaderPlain(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		consumeSlowly(&latencyReader{n: 20, delay: 100 * time.Microsecond}, 100*time.Microsecond)
+	}
+}
+
+// consumeSlowly reads the given reader by waiting the given delay after each read.
+func consumeSlowly(r io.Reader, delay time.Duration) {
+	p := make([]byte, 4096)
+	for {
+		if _, err := r.Read(p); err != nil {
+			return
+		}
+		time.Sleep(delay)
+	}
+}
+
+// latencyReader implements a reader that returns the given number of full reads with a latency.
+type latencyReader struct {
+	n     int
+	delay time.Duration
+}
+
+// Read implements the io.Reader interface.
+func (lr *latencyReader) Read(p []byte) (n int, err error) {
+	if lr.n <= 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(lr.delay)
+	lr.n--
+	return len(p), nil
+}
@@ -0,0 +1,143 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrRecordCorrupt means that a record has an invalid sync marker, length or checksum.
+var ErrRecordCorrupt = errors.New("corrupt record")
+
+// recordMarker is the sync marker that starts each record.
+var recordMarker = []byte{0x89, 'S', 'R', 'E', 'C', 0x0d, 0x0a, 0x1a}
+
+// recordHeaderSize is the size of the record header (sync marker, length and checksum).
+const recordHeaderSize = 16
+
+// recordTable is the CRC32C (Castagnoli) table.
+var recordTable = crc32.MakeTable(crc32.Castagnoli)
+
+// NewRecordWriter returns a RecordWriter that appends records to the given writer.
+// Each record consists of a sync marker, the length (uint32), the CRC32C of the length and the payload
+// (uint32) and the payload.
+func NewRecordWriter(w io.Writer) *RecordWriter {
+	return &RecordWriter{w: w, max: int64(defaultMaxFrameSize)}
+}
+
+// RecordWriter implements the io.Writer interface for writing records. Each write call writes one record.
+type RecordWriter struct {
+	w   io.Writer
+	max int64
+}
+
+// SetMaxRecordSize sets the maximum record (payload) size. The default size is 4 MiB.
+func (rw *RecordWriter) SetMaxRecordSize(size BinaryUnit) {
+	rw.max = int64(size)
+}
+
+// Write implements the io.Writer interface.
+func (rw *RecordWriter) Write(p []byte) (n int, err error) {
+	if err := rw.WriteRecord(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord writes the given bytes as a record.
+func (rw *RecordWriter) WriteRecord(p []byte) error {
+	if int64(len(p)) > rw.max || int64(len(p)) > int64(^uint32(0)) {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(p))
+	}
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(p))
+	copy(record, recordMarker)
+	binary.BigEndian.PutUint32(record[8:12], uint32(len(p)))
+	binary.BigEndian.PutUint32(record[12:16], recordChecksum(record[8:12], p))
+	record = append(record, p...)
+	_, err := rw.w.Write(record)
+	return err
+}
+
+// NewRecordReader returns a RecordReader that reads the records from the given reader.
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{r: &pushbackReader{r: r}, max: int64(defaultMaxFrameSize)}
+}
+
+// RecordReader represents a reader for reading and validating records.
+// When a record is corrupt, the Resync method can be called to skip forward to the next sync marker.
+type RecordReader struct {
+	r   *pushbackReader
+	max int64
+}
+
+// SetMaxRecordSize sets the maximum record (payload) size. The default size is 4 MiB.
+// Records with larger lengths are treated as corrupt.
+func (rr *RecordReader) SetMaxRecordSize(size BinaryUnit) {
+	rr.max = int64(size)
+}
+
+// ReadRecord reads and validates the next record. It returns io.EOF when there are no more records.
+// Corrupt and truncated records return errors that wrap ErrRecordCorrupt and ErrFrameTruncated.
+func (rr *RecordReader) ReadRecord() ([]byte, error) {
+	hdr := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(rr.r, hdr); err == io.ErrUnexpectedEOF {
+		// The rest of the stream is too short for a record.
+		return nil, fmt.Errorf("%w: incomplete header", ErrFrameTruncated)
+	} else if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr[:8], recordMarker) {
+		rr.r.Unread(hdr[1:])
+		return nil, fmt.Errorf("%w: invalid sync marker", ErrRecordCorrupt)
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[8:12]))
+	if size > rr.max {
+		rr.r.Unread(hdr[1:])
+		return nil, fmt.Errorf("%w: invalid length (%d bytes)", ErrRecordCorrupt, size)
+	}
+	p := make([]byte, size)
+	if n, err := io.ReadFull(rr.r, p); err == io.EOF || err == io.ErrUnexpectedEOF {
+		// The length might be corrupt and cover the next records so the bytes are pushed back for resync.
+		rr.r.Unread(append(hdr[1:], p[:n]...))
+		return nil, fmt.Errorf("%w: got %d bytes, want %d bytes", ErrFrameTruncated, n, size)
+	} else if err != nil {
+		return nil, err
+	}
+	if recordChecksum(hdr[8:12], p) != binary.BigEndian.Uint32(hdr[12:16]) {
+		// The payload might contain the next record (i.e. torn write) so it's pushed back for resync.
+		rr.r.Unread(append(hdr[1:], p...))
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrRecordCorrupt)
+	}
+	return p, nil
+}
+
+// Resync skips forward to the next sync marker by using Index and returns the number of skipped bytes.
+// It returns io.EOF if there are no more sync markers.
+func (rr *RecordReader) Resync() (int64, error) {
+	// Keep only the tail of the scanned bytes since the marker is close to the end of them.
+	readSize := 4096
+	tw := NewTailWriter(BinaryUnit(2 * (readSize + len(recordMarker))))
+	index, read, err := Index(io.TeeReader(rr.r, tw), recordMarker, readSize)
+	if err != nil {
+		return read, err
+	} else if index < 0 {
+		return read, io.EOF
+	}
+
+	// Push back the marker and the bytes after it
+	tail := tw.Bytes()
+	rr.r.Unread(tail[int64(len(tail))-(read-index):])
+	return index, nil
+}
+
+// recordChecksum returns the CRC32C of the given length and payload.
+func recordChecksum(length, p []byte) uint32 {
+	crc := crc32.Update(0, recordTable, length)
+	return crc32.Update(crc, recordTable, p)
+}
@@ -0,0 +1,142 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestRecord(t *testing.T) {
+	records := []string{"foo", "", "bar baz", strings.Repeat("qux", 10000)}
+	var buf bytes.Buffer
+	rw := streamy.NewRecordWriter(&buf)
+	for _, s := range records {
+		if err := rw.WriteRecord([]byte(s)); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	}
+	rr := streamy.NewRecordReader(iotest.HalfReader(&buf))
+	for _, s := range records {
+		p, err := rr.ReadRecord()
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(p) != s {
+			t.Errorf("got %v bytes, want %v bytes", len(p), len(s))
+		}
+	}
+	if _, err := rr.ReadRecord(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+
+	rw.SetMaxRecordSize(2)
+	if err := rw.WriteRecord([]byte("foo")); !errors.Is(err, streamy.ErrFrameTooLarge) {
+		t.Errorf("got %v, want %v", err, streamy.ErrFrameTooLarge)
+	}
+}
+
+func TestRecordResync(t *testing.T) {
+	record := func(s string) []byte {
+		var buf bytes.Buffer
+		streamy.NewRecordWriter(&buf).WriteRecord([]byte(s))
+		return buf.Bytes()
+	}
+	corrupt := func(b []byte, i int) []byte {
+		b[i] ^= 0xff
+		return b
+	}
+	concat := func(bs ...[]byte) []byte {
+		return bytes.Join(bs, nil)
+	}
+
+	table := []struct {
+		content []byte
+		want    []string
+		errs    []error
+	}{
+		{
+			// Corrupt payload
+			content: concat(record("foo"), corrupt(record("bar"), 17), record("baz")),
+			want:    []string{"foo", "baz"},
+			errs:    []error{streamy.ErrRecordCorrupt},
+		},
+		{
+			// Garbage between records
+			content: concat(record("foo"), []byte("garbage"), record("bar")),
+			want:    []string{"foo", "bar"},
+			errs:    []error{streamy.ErrRecordCorrupt},
+		},
+		{
+			// Torn record (i.e. partial write) followed by a valid one
+			content: concat(record("foo"), record("bar baz")[:20], record("qux")),
+			want:    []string{"foo", "qux"},
+			errs:    []error{streamy.ErrRecordCorrupt},
+		},
+		{
+			// Corrupt length that covers the rest of the stream
+			content: concat(record("foo"), corrupt(record("bar"), 10), record("baz")),
+			want:    []string{"foo", "baz"},
+			errs:    []error{streamy.ErrFrameTruncated},
+		},
+		{
+			// Truncated stream
+			content: concat(record("foo"), record("bar")[:10]),
+			want:    []string{"foo"},
+			errs:    []error{streamy.ErrFrameTruncated},
+		},
+		{
+			// Large garbage
+			content: concat(record("foo"), bytes.Repeat([]byte("garbage"), 10000), record("bar")),
+			want:    []string{"foo", "bar"},
+			errs:    []error{streamy.ErrRecordCorrupt},
+		},
+	}
+	for _, v := range table {
+		rr := streamy.NewRecordReader(iotest.HalfReader(bytes.NewReader(v.content)))
+		var got []string
+		var errs []error
+		for {
+			p, err := rr.ReadRecord()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				errs = append(errs, err)
+				if _, err := rr.Resync(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("got %v, want nil", err)
+					break
+				}
+				continue
+			}
+			got = append(got, string(p))
+		}
+		if strings.Join(got, ",") != strings.Join(v.want, ",") {
+			t.Errorf("got %q, want %q", got, v.want)
+		}
+		if len(errs) != len(v.errs) {
+			t.Errorf("got %v, want %v", errs, v.errs)
+			continue
+		}
+		for i, err := range errs {
+			if !errors.Is(err, v.errs[i]) {
+				t.Errorf("got %v, want %v", err, v.errs[i])
+			}
+		}
+	}
+}
+
+func BenchmarkRecord(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		streamy.NewRecordWriter(&buf).WriteRecord([]byte("foo bar baz"))
+		streamy.NewRecordReader(&buf).ReadRecord()
+	}
+}
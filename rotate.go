@@ -0,0 +1,216 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewRotatingWriter returns a RotatingWriter that writes to the given file and rotates it when
+// the given size or age is reached. If the size or the age is 0 then it's not checked.
+// The file is opened for appending and created if it doesn't exist.
+func NewRotatingWriter(name string, size BinaryUnit, age time.Duration) (*RotatingWriter, error) {
+	rw := &RotatingWriter{name: name, size: int64(size), age: age, clock: SystemClock}
+	if err := rw.open(); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// RotatingWriter implements the io.WriteCloser interface for writing to a file with rotation.
+// The rotated files are renamed as name.1, name.2, etc. (name.1 is the most recent one) and have
+// the .gz extension when the compression is enabled. It's safe for concurrent use.
+// Note that the limits are checked by the Write calls so a write is never split across files.
+type RotatingWriter struct {
+	mu       sync.Mutex
+	name     string
+	size     int64
+	age      time.Duration
+	backups  int
+	compress bool
+	clock    Clock
+	file     *os.File
+	written  int64
+	openedAt time.Time
+}
+
+// SetMaxBackups sets the maximum number of the rotated files to keep. The older ones are removed.
+// If it's 0 (default) then all the rotated files are kept.
+func (rw *RotatingWriter) SetMaxBackups(n int) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.backups = n
+}
+
+// EnableCompression enables compressing the rotated files by gzip.
+func (rw *RotatingWriter) EnableCompression() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.compress = true
+}
+
+// DisableCompression disables compressing the rotated files.
+func (rw *RotatingWriter) DisableCompression() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.compress = false
+}
+
+// SetClock sets the clock that is used for the age. The default clock is SystemClock.
+// Note that the age of the current file is reset.
+func (rw *RotatingWriter) SetClock(c Clock) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.clock = c
+	rw.openedAt = c.Now()
+}
+
+// Write implements the io.Writer interface. It rotates the file before writing if a limit is reached.
+func (rw *RotatingWriter) Write(p []byte) (n int, err error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file == nil {
+		return 0, ErrWriterClosed
+	}
+	if rw.written > 0 && ((rw.size > 0 && rw.written+int64(len(p)) > rw.size) ||
+		(rw.age > 0 && rw.clock.Now().Sub(rw.openedAt) >= rw.age)) {
+		if err := rw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = rw.file.Write(p)
+	rw.written += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file regardless of the limits.
+func (rw *RotatingWriter) Rotate() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file == nil {
+		return ErrWriterClosed
+	}
+	return rw.rotate()
+}
+
+// Close implements the io.Closer interface.
+func (rw *RotatingWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file == nil {
+		return nil
+	}
+	err := rw.file.Close()
+	rw.file = nil
+	return err
+}
+
+// open opens the file for appending. The caller must hold the lock (if needed).
+func (rw *RotatingWriter) open() error {
+	f, err := os.OpenFile(rw.name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rw.file = f
+	rw.written = fi.Size()
+	rw.openedAt = rw.clock.Now()
+	return nil
+}
+
+// rotate closes the file, shifts the rotated files and opens a new file. The caller must hold the lock.
+// Note that the file is reopened even if the shifting fails so the writes can continue.
+func (rw *RotatingWriter) rotate() error {
+	if err := rw.file.Close(); err != nil {
+		return err
+	}
+	rw.file = nil
+	err := rw.shift()
+	if oerr := rw.open(); err == nil {
+		err = oerr
+	}
+	return err
+}
+
+// shift renames the file as the first rotated file after shifting the others. The caller must hold the lock.
+func (rw *RotatingWriter) shift() error {
+	// Find the last rotated file
+	last := 0
+	for {
+		if _, ok := rw.backupExt(last + 1); !ok {
+			break
+		}
+		last++
+	}
+
+	// Shift the rotated files and remove the ones beyond the limit
+	for i := last; i > 0; i-- {
+		ext, _ := rw.backupExt(i)
+		name := fmt.Sprintf("%s.%d%s", rw.name, i, ext)
+		if rw.backups > 0 && i >= rw.backups {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(name, fmt.Sprintf("%s.%d%s", rw.name, i+1, ext)); err != nil {
+			return err
+		}
+	}
+	name := rw.name + ".1"
+	if err := os.Rename(rw.name, name); err != nil {
+		return err
+	}
+	if rw.compress {
+		return compressFile(name)
+	}
+	return nil
+}
+
+// backupExt returns the extension of the given rotated file (i.e. .gz) and whether it exists or not.
+func (rw *RotatingWriter) backupExt(i int) (string, bool) {
+	name := fmt.Sprintf("%s.%d", rw.name, i)
+	for _, ext := range []string{"", ".gz"} {
+		if _, err := os.Stat(name + ext); err == nil {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// compressFile compresses the given file to a .gz file and removes it.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
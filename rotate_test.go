@@ -0,0 +1,174 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	table := []struct {
+		writes   []string
+		size     streamy.BinaryUnit
+		age      time.Duration
+		advance  time.Duration
+		backups  int
+		compress bool
+		want     map[string]string
+	}{
+		{
+			writes: []string{"foo", "bar", "baz"},
+			size:   6,
+			want:   map[string]string{"log": "baz", "log.1": "foobar"},
+		},
+		{
+			writes: []string{"foo", "bar", "baz", "qux"},
+			size:   3,
+			want:   map[string]string{"log": "qux", "log.1": "baz", "log.2": "bar", "log.3": "foo"},
+		},
+		{
+			writes:  []string{"foo", "bar", "baz", "qux"},
+			size:    3,
+			backups: 2,
+			want:    map[string]string{"log": "qux", "log.1": "baz", "log.2": "bar"},
+		},
+		{
+			writes:   []string{"foo", "bar", "baz"},
+			size:     3,
+			compress: true,
+			want:     map[string]string{"log": "baz", "log.1.gz": "bar", "log.2.gz": "foo"},
+		},
+		{
+			writes:  []string{"foo", "bar", "baz"},
+			age:     time.Minute,
+			advance: 30 * time.Second,
+			want:    map[string]string{"log": "baz", "log.1": "foobar"},
+		},
+		{
+			writes:  []string{"foo", "bar bar bar"},
+			size:    5,
+			advance: time.Hour,
+			want:    map[string]string{"log": "bar bar bar", "log.1": "foo"},
+		},
+	}
+	for _, v := range table {
+		dir := t.TempDir()
+		rw, err := streamy.NewRotatingWriter(filepath.Join(dir, "log"), v.size, v.age)
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		fc := newFakeClock()
+		rw.SetClock(fc)
+		rw.SetMaxBackups(v.backups)
+		if v.compress {
+			rw.EnableCompression()
+		}
+		for _, s := range v.writes {
+			if _, err := io.WriteString(rw, s); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+			fc.Advance(v.advance)
+		}
+		if err := rw.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if _, err := io.WriteString(rw, "foo"); err != streamy.ErrWriterClosed {
+			t.Errorf("got %v, want %v", err, streamy.ErrWriterClosed)
+		}
+
+		got := readDir(t, dir)
+		if len(got) != len(v.want) {
+			t.Errorf("got %v, want %v", got, v.want)
+		}
+		for name, want := range v.want {
+			if got[name] != want {
+				t.Errorf("got %v, want %v", got[name], want)
+			}
+		}
+	}
+}
+
+func TestRotatingWriterConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	rw, err := streamy.NewRotatingWriter(filepath.Join(dir, "log"), 100, 0)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				io.WriteString(rw, "foo bar\n")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := rw.Close(); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+
+	var lines []string
+	for name, content := range readDir(t, dir) {
+		if len(content) > 100 {
+			t.Errorf("%s: got %v bytes, want <=%v bytes", name, len(content), 100)
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(content, "\n"), "\n")...)
+	}
+	sort.Strings(lines)
+	if len(lines) != 1000 || lines[0] != "foo bar" || lines[999] != "foo bar" {
+		t.Errorf("got %v lines, want %v lines", len(lines), 1000)
+	}
+}
+
+func BenchmarkRotatingWriter(b *testing.B) {
+	rw, err := streamy.NewRotatingWriter(filepath.Join(b.TempDir(), "log"), streamy.KiB, 0)
+	if err != nil {
+		b.Fatalf("got %v, want nil", err)
+	}
+	rw.SetMaxBackups(2)
+	for i := 0; i < b.N; i++ {
+		rw.Write([]byte("foo bar baz\n"))
+	}
+	rw.Close()
+}
+
+// readDir returns the (decompressed) contents of the files in the given directory by their names.
+func readDir(t *testing.T, dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	files := map[string]string{}
+	for _, e := range entries {
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		var r io.Reader = f
+		if strings.HasSuffix(e.Name(), ".gz") {
+			if r, err = gzip.NewReader(f); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+		}
+		b, err := io.ReadAll(r)
+		f.Close()
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		files[e.Name()] = string(b)
+	}
+	return files
+}
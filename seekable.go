@@ -0,0 +1,195 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// NewSeekableBuffer returns a SeekableBuffer that makes the given reader seekable.
+// It keeps the bytes in memory up to the given threshold and spills them to a temporary file beyond it.
+func NewSeekableBuffer(r io.Reader, threshold BinaryUnit) *SeekableBuffer {
+	return &SeekableBuffer{r: r, threshold: int64(threshold)}
+}
+
+// SeekableBuffer implements the io.ReadSeekCloser and io.ReaderAt interfaces for any reader.
+// The underlying reader is read lazily, as far as needed by the read and seek calls.
+type SeekableBuffer struct {
+	mu        sync.Mutex
+	r         io.Reader
+	threshold int64
+	mem       []byte
+	file      *os.File
+	size      int64 // size is the number of bytes read from the underlying reader.
+	off       int64
+	err       error
+	closed    bool
+	chunk     []byte
+}
+
+// Read implements the io.Reader interface.
+func (sb *SeekableBuffer) Read(p []byte) (n int, err error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.closed {
+		return 0, os.ErrClosed
+	} else if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Read at least one more chunk if there are no bytes to serve
+	if sb.off >= sb.size {
+		if err := sb.fill(sb.off + 1); err != nil && sb.off >= sb.size {
+			return 0, err
+		}
+	}
+	if rest := sb.size - sb.off; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err = sb.readAt(p, sb.off)
+	sb.off += int64(n)
+	return n, err
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (sb *SeekableBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.closed {
+		return 0, os.ErrClosed
+	} else if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	fillErr := sb.fill(off + int64(len(p)))
+	if off >= sb.size {
+		return 0, fillErr
+	}
+	if rest := sb.size - off; int64(len(p)) > rest {
+		p = p[:rest]
+		if n, err = sb.readAt(p, off); err != nil {
+			return n, err
+		}
+		return n, fillErr
+	}
+	return sb.readAt(p, off)
+}
+
+// Seek implements the io.Seeker interface.
+// Note that seeking relative to the end reads the entire underlying reader.
+func (sb *SeekableBuffer) Seek(offset int64, whence int) (int64, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.closed {
+		return 0, os.ErrClosed
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += sb.off
+	case io.SeekEnd:
+		if err := sb.fill(-1); err != nil && err != io.EOF {
+			return 0, err
+		}
+		offset += sb.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	sb.off = offset
+	return offset, nil
+}
+
+// Size returns the number of bytes read from the underlying reader so far.
+func (sb *SeekableBuffer) Size() int64 {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.size
+}
+
+// Close implements the io.Closer interface. It removes the temporary file (if any).
+func (sb *SeekableBuffer) Close() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.closed {
+		return nil
+	}
+	sb.closed = true
+	sb.mem = nil
+	sb.chunk = nil
+	if sb.file != nil {
+		err := sb.file.Close()
+		if rerr := os.Remove(sb.file.Name()); err == nil {
+			err = rerr
+		}
+		return err
+	}
+	return nil
+}
+
+// fill reads from the underlying reader until the given size is reached or an error occurs.
+// If the size is -1 then it reads until an error occurs (i.e. EOF).
+func (sb *SeekableBuffer) fill(size int64) error {
+	if sb.err != nil {
+		return sb.err
+	}
+	if sb.chunk == nil {
+		sb.chunk = make([]byte, 32*1024)
+	}
+	for size < 0 || sb.size < size {
+		n, err := sb.r.Read(sb.chunk)
+		if n > 0 {
+			if err := sb.store(sb.chunk[:n]); err != nil {
+				sb.err = err
+				return err
+			}
+		}
+		if err != nil {
+			sb.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// store stores the given bytes in memory or in the temporary file.
+func (sb *SeekableBuffer) store(p []byte) error {
+	if sb.file == nil {
+		if sb.size+int64(len(p)) <= sb.threshold {
+			sb.mem = append(sb.mem, p...)
+			sb.size += int64(len(p))
+			return nil
+		}
+
+		// Spill the bytes in memory to the temporary file
+		f, err := os.CreateTemp("", "streamy-*")
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(sb.mem); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return err
+		}
+		sb.file = f
+		sb.mem = nil
+	}
+	if _, err := sb.file.WriteAt(p, sb.size); err != nil {
+		return err
+	}
+	sb.size += int64(len(p))
+	return nil
+}
+
+// readAt reads the stored bytes at the given offset.
+func (sb *SeekableBuffer) readAt(p []byte, off int64) (int, error) {
+	if sb.file != nil {
+		return sb.file.ReadAt(p, off)
+	}
+	return copy(p, sb.mem[off:]), nil
+}
@@ -0,0 +1,100 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestSeekableBuffer(t *testing.T) {
+	content := strings.Repeat("foo bar baz ", 10000)
+
+	table := []struct {
+		reader    io.Reader
+		threshold streamy.BinaryUnit
+		spill     bool
+	}{
+		{streamy.ReaderOnly(bytes.NewBufferString(content)), streamy.MiB, false},
+		{streamy.ReaderOnly(bytes.NewBufferString(content)), 10 * streamy.KiB, true},
+		{iotest.OneByteReader(bytes.NewBufferString(content)), 0, true},
+	}
+	for _, v := range table {
+		dir := t.TempDir()
+		t.Setenv("TMPDIR", dir)
+
+		sb := streamy.NewSeekableBuffer(v.reader, v.threshold)
+
+		// Read
+		p := make([]byte, 11)
+		if _, err := io.ReadFull(sb, p); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(p) != "foo bar baz" {
+			t.Errorf("got %v, want %v", string(p), "foo bar baz")
+		}
+
+		// ReadAt
+		if _, err := sb.ReadAt(p[:3], 20008); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(p[:3]) != "bar" {
+			t.Errorf("got %v, want %v", string(p[:3]), "bar")
+		}
+		if n, err := sb.ReadAt(p, int64(len(content)-3)); err != io.EOF {
+			t.Errorf("got %v, want %v", err, io.EOF)
+		} else if n != 3 {
+			t.Errorf("got %v, want %v", n, 3)
+		}
+
+		// Seek
+		if off, err := sb.Seek(-4, io.SeekEnd); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if off != int64(len(content)-4) {
+			t.Errorf("got %v, want %v", off, len(content)-4)
+		}
+		if b, err := io.ReadAll(sb); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(b) != "baz " {
+			t.Errorf("got %v, want %v", string(b), "baz ")
+		}
+		if _, err := sb.Seek(0, io.SeekStart); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if b, err := io.ReadAll(sb); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if string(b) != content {
+			t.Errorf("got %v bytes, want %v bytes", len(b), len(content))
+		}
+
+		// Spill and cleanup
+		entries, _ := os.ReadDir(dir)
+		if spill := len(entries) > 0; spill != v.spill {
+			t.Errorf("got %v, want %v", spill, v.spill)
+		}
+		if err := sb.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("got %v, want %v", len(entries), 0)
+		}
+		if _, err := sb.Read(p); err != os.ErrClosed {
+			t.Errorf("got %v, want %v", err, os.ErrClosed)
+		}
+	}
+}
+
+func BenchmarkSeekableBuffer(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sb := streamy.NewSeekableBuffer(bytes.NewBufferString("foo bar baz"), streamy.KiB)
+		io.Copy(io.Discard, sb)
+		sb.Seek(0, io.SeekStart)
+		io.Copy(io.Discard, sb)
+		sb.Close()
+	}
+}
@@ -0,0 +1,220 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// ErrSizeMismatch means that the size of a stream doesn't match the expected one.
+var ErrSizeMismatch = errors.New("size mismatch")
+
+// NewSplitter returns a Splitter that splits what it's written to parts of at most the given size.
+// The parts are created by the given function in order, starting from 0 (i.e. for naming them sequentially).
+func NewSplitter(size BinaryUnit, create func(part int) (io.WriteCloser, error)) (*Splitter, error) {
+	if size <= 0 {
+		return nil, errors.New("invalid part size")
+	}
+	return &Splitter{size: int64(size), create: create}, nil
+}
+
+// Splitter implements the io.WriteCloser interface for splitting a stream to parts.
+// The parts are created lazily so there is no empty part. The manifest is complete after the Close call.
+type Splitter struct {
+	size     int64
+	create   func(part int) (io.WriteCloser, error)
+	w        io.WriteCloser
+	h        hash.Hash
+	written  int64 // written is the number of bytes written to the current part.
+	manifest Manifest
+	err      error
+	closed   bool
+}
+
+// Write implements the io.Writer interface.
+func (s *Splitter) Write(p []byte) (n int, err error) {
+	if s.err != nil {
+		return 0, s.err
+	} else if s.closed {
+		return 0, ErrWriterClosed
+	}
+	for len(p) > 0 {
+		if s.w == nil {
+			part := len(s.manifest.Parts)
+			w, err := s.create(part)
+			if err != nil {
+				s.err = fmt.Errorf("part %d: %w", part, err)
+				return n, s.err
+			}
+			s.w, s.h, s.written = w, sha256.New(), 0
+		}
+
+		c := int64(len(p))
+		if rest := s.size - s.written; c > rest {
+			c = rest
+		}
+		m, err := s.w.Write(p[:c])
+		s.h.Write(p[:m])
+		s.written += int64(m)
+		n += m
+		if err == nil && int64(m) < c {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			s.err = fmt.Errorf("part %d: %w", len(s.manifest.Parts), err)
+			return n, s.err
+		}
+		if s.written == s.size {
+			if err := s.closePart(); err != nil {
+				return n, err
+			}
+		}
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// Close implements the io.Closer interface. It closes the last part (if any).
+func (s *Splitter) Close() error {
+	if s.closed {
+		return s.err
+	}
+	s.closed = true
+	if s.w != nil && s.err == nil {
+		return s.closePart()
+	}
+	return s.err
+}
+
+// Manifest returns the manifest of the parts written so far.
+func (s *Splitter) Manifest() Manifest {
+	m := s.manifest
+	m.Parts = append([]ManifestPart(nil), s.manifest.Parts...)
+	return m
+}
+
+// closePart closes the current part and adds it to the manifest.
+func (s *Splitter) closePart() error {
+	part := len(s.manifest.Parts)
+	err := s.w.Close()
+	s.w = nil
+	if err != nil {
+		s.err = fmt.Errorf("part %d: %w", part, err)
+		return s.err
+	}
+	s.manifest.Parts = append(s.manifest.Parts, ManifestPart{
+		Part:   part,
+		Size:   s.written,
+		SHA256: hex.EncodeToString(s.h.Sum(nil)),
+	})
+	s.manifest.Size += s.written
+	return nil
+}
+
+// NewJoiner returns a Joiner that joins the parts of the given manifest. The parts are opened by
+// the given function in order, when they are needed.
+func NewJoiner(m Manifest, open func(part int) (io.ReadCloser, error)) *Joiner {
+	return &Joiner{manifest: m, open: open}
+}
+
+// Joiner implements the io.ReadCloser interface for joining the parts of a Splitter as a single stream.
+// Each part is verified by its size and checksum. It returns an error that wraps ErrSizeMismatch or
+// ErrChecksumMismatch if a part doesn't match the manifest.
+type Joiner struct {
+	manifest Manifest
+	open     func(part int) (io.ReadCloser, error)
+	part     int
+	rc       io.ReadCloser
+	vr       *VerifyingReader
+	read     int64 // read is the number of bytes read from the current part.
+	err      error
+}
+
+// Read implements the io.Reader interface.
+func (j *Joiner) Read(p []byte) (n int, err error) {
+	if j.err != nil {
+		return 0, j.err
+	}
+	for n == 0 && len(p) > 0 {
+		if j.rc == nil {
+			if j.part >= len(j.manifest.Parts) {
+				j.err = io.EOF
+				return 0, j.err
+			}
+			if err := j.openPart(); err != nil {
+				j.err = fmt.Errorf("part %d: %w", j.part, err)
+				return 0, j.err
+			}
+		}
+
+		mp := j.manifest.Parts[j.part]
+		n, err = j.vr.Read(p)
+		j.read += int64(n)
+		if j.read > mp.Size {
+			n -= int(j.read - mp.Size)
+			err = fmt.Errorf("%w: more than %d bytes", ErrSizeMismatch, mp.Size)
+		} else if (err == io.EOF || errors.Is(err, ErrChecksumMismatch)) && j.read != mp.Size {
+			// The size mismatch is reported instead of the checksum one since it's more specific.
+			err = fmt.Errorf("%w: got %d bytes, want %d bytes", ErrSizeMismatch, j.read, mp.Size)
+		}
+		if err == io.EOF {
+			err = j.rc.Close()
+			j.rc, j.vr = nil, nil
+			j.part++
+		}
+		if err != nil {
+			j.err = fmt.Errorf("part %d: %w", mp.Part, err)
+			return n, j.err
+		}
+	}
+	return n, nil
+}
+
+// Close implements the io.Closer interface. It closes the current part (if any).
+func (j *Joiner) Close() error {
+	if j.err == nil {
+		j.err = os.ErrClosed
+	}
+	if j.rc != nil {
+		err := j.rc.Close()
+		j.rc, j.vr = nil, nil
+		return err
+	}
+	return nil
+}
+
+// openPart opens the current part.
+func (j *Joiner) openPart() error {
+	mp := j.manifest.Parts[j.part]
+	rc, err := j.open(j.part)
+	if err != nil {
+		return err
+	}
+	vr, err := NewVerifyingReader(rc, sha256.New(), mp.SHA256)
+	if err != nil {
+		rc.Close()
+		return err
+	}
+	j.rc, j.vr, j.read = rc, vr, 0
+	return nil
+}
+
+// Manifest represents the manifest of the parts of a stream.
+type Manifest struct {
+	Size  int64          `json:"size"`
+	Parts []ManifestPart `json:"parts"`
+}
+
+// ManifestPart represents a part in a manifest.
+type ManifestPart struct {
+	Part   int    `json:"part"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
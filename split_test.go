@@ -0,0 +1,132 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestSplitterJoiner(t *testing.T) {
+	content := strings.Repeat("foo bar baz ", 100)
+
+	table := []struct {
+		content string
+		size    streamy.BinaryUnit
+		parts   int
+		corrupt func(parts [][]byte)
+		err     error
+	}{
+		{content, streamy.KiB, 2, nil, nil},
+		{content, 100, 12, nil, nil},
+		{content, 1200, 1, nil, nil},
+		{"", streamy.KiB, 0, nil, nil},
+		{content, 100, 12, func(parts [][]byte) { parts[3][0] = 'x' }, streamy.ErrChecksumMismatch},
+		{content, 100, 12, func(parts [][]byte) { parts[3] = parts[3][:99] }, streamy.ErrSizeMismatch},
+		{content, 100, 12, func(parts [][]byte) { parts[3] = append(parts[3], 'x') }, streamy.ErrSizeMismatch},
+	}
+	for _, v := range table {
+		// Split
+		var parts [][]byte
+		s, err := streamy.NewSplitter(v.size, func(part int) (io.WriteCloser, error) {
+			if part != len(parts) {
+				t.Errorf("got %v, want %v", part, len(parts))
+			}
+			parts = append(parts, nil)
+			return &partWriter{parts: &parts, part: part}, nil
+		})
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if _, err := io.Copy(s, strings.NewReader(v.content)); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if err := s.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if len(parts) != v.parts {
+			t.Errorf("got %v, want %v", len(parts), v.parts)
+		}
+
+		// Manifest
+		b, err := json.Marshal(s.Manifest())
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		var m streamy.Manifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if m.Size != int64(len(v.content)) {
+			t.Errorf("got %v, want %v", m.Size, len(v.content))
+		} else if len(m.Parts) != v.parts {
+			t.Errorf("got %v, want %v", len(m.Parts), v.parts)
+		}
+
+		// Join
+		if v.corrupt != nil {
+			v.corrupt(parts)
+		}
+		j := streamy.NewJoiner(m, func(part int) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(parts[part])), nil
+		})
+		b, err = io.ReadAll(j)
+		if !errors.Is(err, v.err) {
+			t.Errorf("got %v, want %v", err, v.err)
+		} else if v.err == nil && string(b) != v.content {
+			t.Errorf("got %v, want %v", string(b), v.content)
+		} else if v.err != nil && len(b) > 400 {
+			t.Errorf("got %v bytes, want <=%v bytes", len(b), 400)
+		}
+		if err := j.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	}
+
+	if _, err := streamy.NewSplitter(0, nil); err == nil {
+		t.Errorf("got nil, want error")
+	}
+}
+
+func BenchmarkSplitter(b *testing.B) {
+	content := []byte(strings.Repeat("foo bar baz ", 100))
+	for i := 0; i < b.N; i++ {
+		s, _ := streamy.NewSplitter(100, func(part int) (io.WriteCloser, error) {
+			return nopWriteCloser{io.Discard}, nil
+		})
+		s.Write(content)
+		s.Close()
+	}
+}
+
+// partWriter implements a writer for the parts of a Splitter.
+type partWriter struct {
+	parts *[][]byte
+	part  int
+}
+
+// Write implements the io.Writer interface.
+func (pw *partWriter) Write(p []byte) (n int, err error) {
+	(*pw.parts)[pw.part] = append((*pw.parts)[pw.part], p...)
+	return len(p), nil
+}
+
+// Close implements the io.Closer interface.
+func (pw *partWriter) Close() error {
+	return nil
+}
+
+// nopWriteCloser adds a no-op Close method to a writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements the io.Closer interface.
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -10,28 +10,36 @@ import (
 )
 
 // Index returns the index of the first instance of the given byte slice, number of bytes read and error if any.
+// The reads can be short (i.e. pipes) so the search can span any number of reads.
 func Index(r io.Reader, search []byte, readSize int) (index int64, read int64, err error) {
 	if readSize == 0 {
 		readSize = 4096
 	}
 	tailLen := len(search)
 	b := make([]byte, readSize+tailLen)
-	var i, n int
-	//n, err = r.Read(b[tailLen:]) // err is checked below.
+	var i, n, l int // l is the number of valid bytes in b.
 	for {
 		read += int64(n)
-		i = bytes.Index(b, search)
+		l += n
+		i = bytes.Index(b[:l], search)
 		if i > -1 {
-			// Subtract the tail length since the bytes.Index method uses the entire byte slice.
-			return index + int64(i-tailLen), read, nil
+			// The index is the offset of the first byte in the byte slice.
+			return index + int64(i), read, nil
 		} else if err != nil {
 			if err == io.EOF {
 				return -1, read, nil
 			}
 			return -1, read, err
 		}
-		copy(b, b[readSize:])        // Copy tail bytes to the beginning of the byte slice.
-		index += int64(n)            // Update index before the read call.
-		n, err = r.Read(b[tailLen:]) // err is checked above.
+		// Keep the tail bytes since the search might span multiple reads.
+		// Note that the reads can be short so only the valid bytes are used.
+		keep := tailLen - 1
+		if keep > l {
+			keep = l
+		}
+		copy(b, b[l-keep:l])               // Copy tail bytes to the beginning of the byte slice.
+		index += int64(l - keep)           // Update index before the read call.
+		l = keep                           // Only the tail bytes are valid now.
+		n, err = r.Read(b[l : l+readSize]) // err is checked above.
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/devfacet/streamy"
 )
@@ -96,6 +97,27 @@ func TestIndex(t *testing.T) {
 			index:    -1,
 			read:     14,
 		},
+		{
+			reader:   iotest.OneByteReader(bytes.NewBufferString("this is a test")),
+			search:   []byte("test"),
+			readSize: 4,
+			index:    10,
+			read:     14,
+		},
+		{
+			reader:   iotest.HalfReader(bytes.NewBufferString("this is a test")),
+			search:   []byte("is a"),
+			readSize: 4,
+			index:    5,
+			read:     10,
+		},
+		{
+			reader:   bytes.NewBuffer([]byte{0x01, 0x00, 0x00}),
+			search:   []byte{0x00, 0x00},
+			readSize: 0,
+			index:    1,
+			read:     3,
+		},
 	}
 	for _, v := range table {
 		index, read, err := streamy.Index(v.reader, v.search, v.readSize)
@@ -0,0 +1,239 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"bytes"
+	"io"
+)
+
+// TailBytes returns the last given size of bytes of the given reader.
+// If the reader can seek in practice then it reads from the end instead of consuming the entire stream.
+func TailBytes(r io.Reader, size BinaryUnit) ([]byte, error) {
+	if size < 0 {
+		size = 0
+	}
+	if s, ok := r.(io.Seeker); ok && probeSeek(s) {
+		cur, end, err := seekBounds(s)
+		if err != nil {
+			return nil, err
+		}
+		start := end - int64(size)
+		if start < cur {
+			start = cur
+		}
+		return readRange(r, s, start, end)
+	}
+	tw := NewTailWriter(size)
+	if _, err := io.Copy(tw, r); err != nil {
+		return nil, err
+	}
+	return tw.Bytes(), nil
+}
+
+// TailLines returns the last given number of lines of the given reader.
+// If the reader can seek in practice then it reads backward from the end instead of consuming the entire stream.
+func TailLines(r io.Reader, n int) ([]byte, error) {
+	if s, ok := r.(io.Seeker); ok && probeSeek(s) {
+		cur, end, err := seekBounds(s)
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return []byte{}, nil
+		}
+
+		// Read backward until the given number of line breaks found.
+		// Note that the line break at the end of the stream doesn't start a new line.
+		start := cur
+		buf := make([]byte, 4096)
+		for pos := end; pos > cur && start == cur; {
+			c := int64(len(buf))
+			if pos-c < cur {
+				c = pos - cur
+			}
+			pos -= c
+			if _, err := s.Seek(pos, io.SeekStart); err != nil {
+				return nil, err
+			}
+			if _, err := io.ReadFull(r, buf[:c]); err != nil {
+				return nil, err
+			}
+			for i := c - 1; i >= 0; i-- {
+				if buf[i] != '\n' || pos+i == end-1 {
+					continue
+				}
+				if n--; n == 0 {
+					start = pos + i + 1
+					break
+				}
+			}
+		}
+		return readRange(r, s, start, end)
+	}
+	tw := NewTailLinesWriter(n)
+	if _, err := io.Copy(tw, r); err != nil {
+		return nil, err
+	}
+	return tw.Bytes(), nil
+}
+
+// seekBounds returns the current and the end offsets of the given seeker.
+func seekBounds(s io.Seeker) (cur int64, end int64, err error) {
+	if cur, err = s.Seek(0, io.SeekCurrent); err != nil {
+		return 0, 0, err
+	}
+	if end, err = s.Seek(0, io.SeekEnd); err != nil {
+		return 0, 0, err
+	}
+	return cur, end, nil
+}
+
+// readRange reads the bytes between the given offsets and leaves the reader at the end offset.
+func readRange(r io.Reader, s io.Seeker, start, end int64) ([]byte, error) {
+	if _, err := s.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b := make([]byte, end-start)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewTailWriter returns a TailWriter that keeps the last given size of bytes.
+func NewTailWriter(size BinaryUnit) *TailWriter {
+	if size < 0 {
+		size = 0
+	}
+	return &TailWriter{buf: make([]byte, 0, int(size))}
+}
+
+// NewTailLinesWriter returns a TailWriter that keeps the last given number of lines.
+// The lines longer than the maximum line length (see SetMaxLineLength) are truncated to their last bytes.
+func NewTailLinesWriter(n int) *TailWriter {
+	if n < 0 {
+		n = 0
+	}
+	return &TailWriter{lines: make([][]byte, n), linesMode: true, maxLine: int(defaultMaxLineLength)}
+}
+
+// defaultMaxLineLength is the default maximum line length of the lines mode.
+const defaultMaxLineLength = 64 * KiB
+
+// TailWriter implements the io.Writer interface for keeping the tail of a stream with bounded memory.
+type TailWriter struct {
+	// Bytes mode
+	buf []byte // buf is a ring buffer.
+	pos int
+
+	// Lines mode
+	linesMode bool
+	lines     [][]byte // lines is a ring buffer of the complete lines.
+	head      int
+	count     int
+	partial   []byte
+	maxLine   int
+}
+
+// SetMaxLineLength sets the maximum line length of the lines mode. The default length is 64 KiB.
+func (tw *TailWriter) SetMaxLineLength(size BinaryUnit) {
+	if size <= 0 {
+		size = defaultMaxLineLength
+	}
+	tw.maxLine = int(size)
+}
+
+// Write implements the io.Writer interface.
+func (tw *TailWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if tw.linesMode {
+		tw.writeLines(p)
+		return n, nil
+	}
+
+	size := cap(tw.buf)
+	if size == 0 {
+		return n, nil
+	}
+	if len(p) >= size {
+		// Keep the last bytes only
+		tw.buf = append(tw.buf[:0], p[len(p)-size:]...)
+		tw.pos = 0
+		return n, nil
+	}
+	for len(p) > 0 {
+		if len(tw.buf) < size {
+			// The ring buffer is not full yet
+			c := size - len(tw.buf)
+			if c > len(p) {
+				c = len(p)
+			}
+			tw.buf = append(tw.buf, p[:c]...)
+			p = p[c:]
+			continue
+		}
+		c := copy(tw.buf[tw.pos:], p)
+		tw.pos = (tw.pos + c) % size
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// writeLines writes the given bytes in the lines mode.
+func (tw *TailWriter) writeLines(p []byte) {
+	if len(tw.lines) == 0 {
+		return
+	}
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			tw.partial = appendTail(tw.partial, p, tw.maxLine)
+			return
+		}
+		line := appendTail(tw.partial, p[:i+1], tw.maxLine)
+		p = p[i+1:]
+
+		// Push the line to the ring buffer and reuse the oldest one for the next partial line
+		idx := (tw.head + tw.count) % len(tw.lines)
+		if tw.count == len(tw.lines) {
+			tw.head = (tw.head + 1) % len(tw.lines)
+		} else {
+			tw.count++
+		}
+		tw.partial = tw.lines[idx][:0]
+		tw.lines[idx] = line
+	}
+}
+
+// appendTail appends the given bytes to the given line and keeps the last given size of bytes of it.
+func appendTail(line, p []byte, size int) []byte {
+	if len(p) >= size {
+		return append(line[:0], p[len(p)-size:]...)
+	}
+	if over := len(line) + len(p) - size; over > 0 {
+		line = line[:copy(line, line[over:])]
+	}
+	return append(line, p...)
+}
+
+// Bytes returns the tail bytes.
+func (tw *TailWriter) Bytes() []byte {
+	if tw.linesMode {
+		var b []byte
+		i, l := 0, tw.count
+		if len(tw.partial) > 0 && l == len(tw.lines) {
+			// The partial line counts as the last line.
+			i++
+		}
+		for ; i < l; i++ {
+			b = append(b, tw.lines[(tw.head+i)%len(tw.lines)]...)
+		}
+		return append(b, tw.partial...)
+	}
+
+	b := make([]byte, 0, len(tw.buf))
+	b = append(b, tw.buf[tw.pos:]...)
+	return append(b, tw.buf[:tw.pos]...)
+}
@@ -0,0 +1,124 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestTailWriter(t *testing.T) {
+	table := []struct {
+		writes []string
+		lines  bool
+		n      int
+		want   string
+	}{
+		{[]string{"foo bar baz"}, false, 3, "baz"},
+		{[]string{"foo", " bar", " baz"}, false, 5, "r baz"},
+		{[]string{"f", "o", "o"}, false, 5, "foo"},
+		{[]string{"foo"}, false, 0, ""},
+		{[]string{"foo"}, false, -1, ""},
+		{[]string{"foo\nbar\nbaz\n"}, true, 2, "bar\nbaz\n"},
+		{[]string{"foo\nbar\nbaz"}, true, 2, "bar\nbaz"},
+		{[]string{"fo", "o\nba", "r\n", "baz\nqux"}, true, 3, "bar\nbaz\nqux"},
+		{[]string{"foo\n"}, true, 2, "foo\n"},
+		{[]string{"foo\nbar\n"}, true, 0, ""},
+	}
+	for _, v := range table {
+		var tw *streamy.TailWriter
+		if v.lines {
+			tw = streamy.NewTailLinesWriter(v.n)
+		} else {
+			tw = streamy.NewTailWriter(streamy.BinaryUnit(v.n))
+		}
+		for _, s := range v.writes {
+			io.WriteString(tw, s)
+		}
+		if s := string(tw.Bytes()); s != v.want {
+			t.Errorf("got %q, want %q", s, v.want)
+		}
+	}
+
+	// The long lines should be truncated to their last bytes.
+	tw := streamy.NewTailLinesWriter(2)
+	tw.SetMaxLineLength(4)
+	for _, s := range []string{"foo\n", "barbazqux", "quux\n", "qu"} {
+		io.WriteString(tw, s)
+	}
+	if s := string(tw.Bytes()); s != "uux\nqu" {
+		t.Errorf("got %q, want %q", s, "uux\nqu")
+	}
+	tw = streamy.NewTailLinesWriter(2)
+	for i := 0; i < 100; i++ {
+		io.WriteString(tw, strings.Repeat("foo bar baz ", 1000))
+	}
+	if l := len(tw.Bytes()); l != int(64*streamy.KiB) {
+		t.Errorf("got %v, want %v", l, 64*streamy.KiB)
+	}
+}
+
+func TestTail(t *testing.T) {
+	table := []struct {
+		content string
+		size    streamy.BinaryUnit
+		lines   int
+		want    string
+	}{
+		{"foo bar baz", 3, 0, "baz"},
+		{"foo bar baz", 20, 0, "foo bar baz"},
+		{"foo bar baz", -1, 0, ""},
+		{"foo\nbar\nbaz\n", 0, 2, "bar\nbaz\n"},
+		{"foo\nbar\nbaz", 0, 2, "bar\nbaz"},
+		{"foo\nbar\nbaz", 0, 5, "foo\nbar\nbaz"},
+		{"foo\n\n\n", 0, 2, "\n\n"},
+		{strings.Repeat("foo bar baz\n", 1000), 0, 500, strings.Repeat("foo bar baz\n", 500)},
+	}
+	dir := t.TempDir()
+	for i, v := range table {
+		name := filepath.Join(dir, strings.Repeat("f", i+1))
+		os.WriteFile(name, []byte(v.content), 0600)
+		f, _ := os.Open(name)
+		defer f.Close()
+
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		defer pr.Close()
+		go func(content string) {
+			pw.Write([]byte(content))
+			pw.Close()
+		}(v.content)
+
+		for _, r := range []io.Reader{f, pr, streamy.ReaderOnly(bytes.NewBufferString(v.content))} {
+			var b []byte
+			var err error
+			if v.lines > 0 {
+				b, err = streamy.TailLines(r, v.lines)
+			} else {
+				b, err = streamy.TailBytes(r, v.size)
+			}
+			if err != nil {
+				t.Errorf("got %v, want nil", err)
+			} else if string(b) != v.want {
+				t.Errorf("got %q, want %q", b, v.want)
+			}
+		}
+	}
+}
+
+func BenchmarkTailWriter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tw := streamy.NewTailLinesWriter(2)
+		io.WriteString(tw, "foo\nbar\nbaz\n")
+		tw.Bytes()
+	}
+}
@@ -0,0 +1,154 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrIdleTimeout means that no bytes are read for the idle timeout.
+// It matches os.ErrDeadlineExceeded by errors.Is.
+var ErrIdleTimeout = fmt.Errorf("idle timeout: %w", os.ErrDeadlineExceeded)
+
+// NewTimeoutReader returns a TimeoutReader that fails when a read takes longer than the given idle timeout.
+// If the idle timeout is 0 then only the deadline (see SetDeadline) is enforced.
+func NewTimeoutReader(r io.Reader, idle time.Duration) *TimeoutReader {
+	tr := &TimeoutReader{r: r, idle: idle}
+	if rd, ok := r.(readDeadliner); ok {
+		tr.rd = rd
+	}
+	return tr
+}
+
+// TimeoutReader implements the io.Reader interface for reading with an idle timeout and an overall deadline.
+// It uses SetReadDeadline when the underlying reader supports it (i.e. os.File pipes and net.Conn).
+// Otherwise the reads are made on a background goroutine and an abandoned read is kept for the next
+// Read call so no bytes are lost. Note that such a read blocks its goroutine until the underlying reader
+// returns (i.e. closing the underlying reader releases it).
+type TimeoutReader struct {
+	mu       sync.Mutex
+	r        io.Reader
+	rd       readDeadliner
+	idle     time.Duration
+	deadline time.Time
+	pending  chan timeoutResult
+	buf      []byte
+	rest     []byte
+	err      error
+}
+
+// SetDeadline sets the overall deadline. A zero value means no deadline.
+func (tr *TimeoutReader) SetDeadline(t time.Time) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.deadline = t
+}
+
+// Read implements the io.Reader interface.
+// It returns ErrIdleTimeout when the idle timeout is exceeded and os.ErrDeadlineExceeded when
+// the deadline is exceeded. An idle timeout isn't permanent so the next Read call can be retried.
+func (tr *TimeoutReader) Read(p []byte) (n int, err error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	// Serve the bytes left from a previous background read first
+	if len(tr.rest) > 0 {
+		n = copy(p, tr.rest)
+		tr.rest = tr.rest[n:]
+		return n, nil
+	} else if tr.err != nil {
+		return 0, tr.err
+	} else if len(p) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	if !tr.deadline.IsZero() && !now.Before(tr.deadline) {
+		return 0, os.ErrDeadlineExceeded
+	}
+	deadline := tr.deadline
+	if tr.idle > 0 && (deadline.IsZero() || now.Add(tr.idle).Before(deadline)) {
+		deadline = now.Add(tr.idle)
+	}
+
+	if tr.rd != nil && tr.pending == nil {
+		if err := tr.rd.SetReadDeadline(deadline); err == nil {
+			n, err = tr.r.Read(p)
+			if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+				err = tr.timeoutErr()
+			}
+			return n, err
+		}
+		// Fall back to the background reads (i.e. os.ErrNoDeadline)
+		tr.rd = nil
+	}
+	if deadline.IsZero() && tr.pending == nil {
+		return tr.r.Read(p)
+	}
+	return tr.readAsync(p, deadline)
+}
+
+// readAsync reads on a background goroutine and waits for the result until the given deadline.
+// The caller must hold the lock.
+func (tr *TimeoutReader) readAsync(p []byte, deadline time.Time) (n int, err error) {
+	if tr.pending == nil {
+		if cap(tr.buf) < len(p) {
+			tr.buf = make([]byte, len(p))
+		}
+		buf := tr.buf[:len(p)]
+		ch := make(chan timeoutResult, 1)
+		go func() {
+			n, err := tr.r.Read(buf)
+			ch <- timeoutResult{p: buf[:n], err: err}
+		}()
+		tr.pending = ch
+	}
+
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case res := <-tr.pending:
+		tr.pending = nil
+		n = copy(p, res.p)
+		tr.rest = res.p[n:]
+		if res.err != nil {
+			if len(tr.rest) > 0 {
+				tr.err = res.err
+				return n, nil
+			}
+			return n, res.err
+		}
+		return n, nil
+	case <-expired:
+		return 0, tr.timeoutErr()
+	}
+}
+
+// timeoutErr returns the error for an expired read. The caller must hold the lock.
+func (tr *TimeoutReader) timeoutErr() error {
+	if !tr.deadline.IsZero() && !time.Now().Before(tr.deadline) {
+		return os.ErrDeadlineExceeded
+	}
+	return ErrIdleTimeout
+}
+
+// readDeadliner is the interface that wraps the SetReadDeadline method (i.e. os.File and net.Conn).
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// timeoutResult represents the result of a background read.
+type timeoutResult struct {
+	p   []byte
+	err error
+}
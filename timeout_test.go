@@ -0,0 +1,107 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestTimeoutReader(t *testing.T) {
+	delay := 20 * time.Millisecond
+	table := []struct {
+		name     string
+		pipe     func() (io.Reader, io.WriteCloser)
+		deadline bool
+		err      error
+	}{
+		{"os.Pipe", osPipe, false, streamy.ErrIdleTimeout},
+		{"os.Pipe", osPipe, true, os.ErrDeadlineExceeded},
+		{"net.Pipe", netPipe, false, streamy.ErrIdleTimeout},
+		{"io.Pipe", ioPipe, false, streamy.ErrIdleTimeout},
+		{"io.Pipe", ioPipe, true, os.ErrDeadlineExceeded},
+	}
+	for _, v := range table {
+		r, w := v.pipe()
+		tr := streamy.NewTimeoutReader(r, delay)
+		if v.deadline {
+			tr.SetDeadline(time.Now().Add(delay / 2))
+		}
+
+		// Nothing is written so the read should time out
+		p := make([]byte, 3)
+		start := time.Now()
+		if _, err := tr.Read(p); err != v.err {
+			t.Errorf("%s: got %v, want %v", v.name, err, v.err)
+		} else if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("%s: got %v, want %v", v.name, err, os.ErrDeadlineExceeded)
+		} else if d := time.Since(start); d > delay*10 {
+			t.Errorf("%s: got %v, want <%v", v.name, d, delay*10)
+		}
+
+		// The bytes written after an idle timeout should be read (i.e. not lost by the abandoned read)
+		if !v.deadline {
+			go func() {
+				io.WriteString(w, "foo bar")
+				w.Close()
+			}()
+			if b, err := io.ReadAll(tr); err != nil {
+				t.Errorf("%s: got %v, want nil", v.name, err)
+			} else if string(b) != "foo bar" {
+				t.Errorf("%s: got %v, want %v", v.name, string(b), "foo bar")
+			}
+		} else if _, err := tr.Read(p); err != os.ErrDeadlineExceeded {
+			t.Errorf("%s: got %v, want %v", v.name, err, os.ErrDeadlineExceeded)
+		}
+		w.Close()
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+func BenchmarkTimeoutReader(b *testing.B) {
+	r, w := io.Pipe()
+	go func() {
+		p := []byte("foo bar baz")
+		for {
+			if _, err := w.Write(p); err != nil {
+				return
+			}
+		}
+	}()
+	tr := streamy.NewTimeoutReader(r, time.Second)
+	p := make([]byte, 11)
+	for i := 0; i < b.N; i++ {
+		tr.Read(p)
+	}
+	r.Close()
+}
+
+// osPipe returns an os.Pipe which supports read deadlines.
+func osPipe() (io.Reader, io.WriteCloser) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		panic(err)
+	}
+	return r, w
+}
+
+// netPipe returns a net.Pipe which supports read deadlines.
+func netPipe() (io.Reader, io.WriteCloser) {
+	r, w := net.Pipe()
+	return r, w
+}
+
+// ioPipe returns an io.Pipe which doesn't support read deadlines.
+func ioPipe() (io.Reader, io.WriteCloser) {
+	r, w := io.Pipe()
+	return r, w
+}
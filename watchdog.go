@@ -0,0 +1,170 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// NewWatchdog returns a Watchdog that detects stalls when no bytes move for the given idle period.
+func NewWatchdog(idle time.Duration) *Watchdog {
+	return &Watchdog{idle: idle, clock: SystemClock}
+}
+
+// Watchdog represents a stall detector for streams. The streams are watched by wrapping their readers or
+// writers (i.e. a Progress) by the Reader and Writer methods.
+type Watchdog struct {
+	mu      sync.Mutex
+	idle    time.Duration
+	clock   Clock
+	last    time.Time
+	onStall func(idle time.Duration)
+	timer   ClockTimer
+	cancel  context.CancelFunc
+	running bool
+	gen     int // gen is the generation of the current run so the previous runs can't affect it.
+}
+
+// SetClock sets the clock that is used for the idle period. The default clock is SystemClock.
+func (wd *Watchdog) SetClock(c Clock) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.clock = c
+}
+
+// OnStall sets the function that is called (once) when a stall is detected.
+func (wd *Watchdog) OnStall(f func(idle time.Duration)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.onStall = f
+}
+
+// Start starts watching and returns a context that is cancelled when a stall is detected.
+// The watching stops when a stall is detected, the given context is done or the Stop method is called.
+func (wd *Watchdog) Start(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	wd.mu.Lock()
+	if wd.running {
+		wd.stop()
+	}
+	wd.gen++
+	gen := wd.gen
+	wd.cancel = cancel
+	wd.running = true
+	wd.last = wd.clock.Now()
+	wd.timer = wd.clock.AfterFunc(wd.idle, func() { wd.check(gen) })
+	wd.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		wd.mu.Lock()
+		defer wd.mu.Unlock()
+		if wd.gen == gen {
+			wd.stop()
+		}
+	}()
+	return ctx
+}
+
+// Stop stops watching.
+func (wd *Watchdog) Stop() {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.stop()
+}
+
+// Touch records an activity. It's called by the wrapped readers and writers when bytes move.
+func (wd *Watchdog) Touch() {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.last = wd.clock.Now()
+}
+
+// Idle returns the time passed since the last activity.
+func (wd *Watchdog) Idle() time.Duration {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.last.IsZero() {
+		return 0
+	}
+	return wd.clock.Now().Sub(wd.last)
+}
+
+// Reader returns an io.Reader that records an activity for each read that returns bytes.
+func (wd *Watchdog) Reader(r io.Reader) io.Reader {
+	return &watchdogReader{r: r, wd: wd}
+}
+
+// Writer returns an io.Writer that records an activity for each write that writes bytes.
+func (wd *Watchdog) Writer(w io.Writer) io.Writer {
+	return &watchdogWriter{w: w, wd: wd}
+}
+
+// check checks the idle time of the given run and either fires the stall or schedules the next check.
+func (wd *Watchdog) check(gen int) {
+	wd.mu.Lock()
+	if !wd.running || wd.gen != gen {
+		wd.mu.Unlock()
+		return
+	}
+	idle := wd.clock.Now().Sub(wd.last)
+	if idle < wd.idle {
+		wd.timer = wd.clock.AfterFunc(wd.idle-idle, func() { wd.check(gen) })
+		wd.mu.Unlock()
+		return
+	}
+	onStall := wd.onStall
+	wd.stop()
+	wd.mu.Unlock()
+
+	if onStall != nil {
+		onStall(idle)
+	}
+}
+
+// stop stops watching and cancels the context. The caller must hold the lock.
+func (wd *Watchdog) stop() {
+	if !wd.running {
+		return
+	}
+	wd.running = false
+	if wd.timer != nil {
+		wd.timer.Stop()
+		wd.timer = nil
+	}
+	wd.cancel()
+}
+
+// watchdogReader implements an io.Reader that records activities.
+type watchdogReader struct {
+	r  io.Reader
+	wd *Watchdog
+}
+
+// Read implements the io.Reader interface.
+func (wr *watchdogReader) Read(p []byte) (n int, err error) {
+	n, err = wr.r.Read(p)
+	if n > 0 {
+		wr.wd.Touch()
+	}
+	return n, err
+}
+
+// watchdogWriter implements an io.Writer that records activities.
+type watchdogWriter struct {
+	w  io.Writer
+	wd *Watchdog
+}
+
+// Write implements the io.Writer interface.
+func (ww *watchdogWriter) Write(p []byte) (n int, err error) {
+	n, err = ww.w.Write(p)
+	if n > 0 {
+		ww.wd.Touch()
+	}
+	return n, err
+}
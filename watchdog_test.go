@@ -0,0 +1,158 @@
+// Streamy
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package streamy_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/devfacet/streamy"
+)
+
+func TestWatchdog(t *testing.T) {
+	idle := time.Second
+	table := []struct {
+		steps []func(w io.Writer, fc *fakeClock)
+		stall bool
+		idle  time.Duration
+	}{
+		{
+			steps: []func(w io.Writer, fc *fakeClock){
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+				func(w io.Writer, fc *fakeClock) { io.WriteString(w, "foo") },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+				func(w io.Writer, fc *fakeClock) { io.WriteString(w, "bar") },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+			},
+			stall: false,
+		},
+		{
+			steps: []func(w io.Writer, fc *fakeClock){
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle) },
+			},
+			stall: true,
+			idle:  idle,
+		},
+		{
+			steps: []func(w io.Writer, fc *fakeClock){
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+				func(w io.Writer, fc *fakeClock) { io.WriteString(w, "foo") },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle / 2) },
+			},
+			stall: true,
+			idle:  idle,
+		},
+		{
+			steps: []func(w io.Writer, fc *fakeClock){
+				func(w io.Writer, fc *fakeClock) { io.WriteString(w, "") },
+				func(w io.Writer, fc *fakeClock) { fc.Advance(idle) },
+			},
+			stall: true,
+			idle:  idle,
+		},
+	}
+	for _, v := range table {
+		fc := newFakeClock()
+		wd := streamy.NewWatchdog(idle)
+		wd.SetClock(fc)
+		var stalled time.Duration
+		wd.OnStall(func(idle time.Duration) { stalled = idle })
+		ctx := wd.Start(context.Background())
+
+		progress := streamy.Progress{}
+		w := wd.Writer(&progress)
+		for _, step := range v.steps {
+			step(w, fc)
+		}
+		if stall := ctx.Err() != nil; stall != v.stall {
+			t.Errorf("got %v, want %v", stall, v.stall)
+		} else if stalled != v.idle {
+			t.Errorf("got %v, want %v", stalled, v.idle)
+		}
+		wd.Stop()
+		if ctx.Err() == nil {
+			t.Errorf("got nil, want %v", context.Canceled)
+		}
+	}
+}
+
+func TestWatchdogRestart(t *testing.T) {
+	idle := time.Second
+	fc := newFakeClock()
+	wd := streamy.NewWatchdog(idle)
+	wd.SetClock(fc)
+
+	// The second run shouldn't be stopped by the first one.
+	first := wd.Start(context.Background())
+	second := wd.Start(context.Background())
+	if first.Err() == nil {
+		t.Errorf("got nil, want %v", context.Canceled)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if second.Err() != nil {
+		t.Errorf("got %v, want nil", second.Err())
+	}
+
+	// The timers of the first run shouldn't affect the second one.
+	fc.Advance(idle / 2)
+	wd.Touch()
+	fc.Advance(idle / 2)
+	if second.Err() != nil {
+		t.Errorf("got %v, want nil", second.Err())
+	}
+	fc.Advance(idle / 2)
+	if second.Err() == nil {
+		t.Errorf("got nil, want %v", context.Canceled)
+	}
+}
+
+func TestWatchdogReader(t *testing.T) {
+	delay := 10 * time.Millisecond
+	wd := streamy.NewWatchdog(delay * 5)
+	ctx := wd.Start(context.Background())
+	defer wd.Stop()
+
+	// The reader is slow but it moves bytes so there should be no stall.
+	if _, err := io.Copy(io.Discard, wd.Reader(&slowReader{content: "foo bar", delay: delay})); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if ctx.Err() != nil {
+		t.Errorf("got %v, want nil", ctx.Err())
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay * 50):
+		t.Errorf("got no stall, want stall")
+	}
+	if wd.Idle() < delay*5 {
+		t.Errorf("got %v, want >=%v", wd.Idle(), delay*5)
+	}
+}
+
+func TestProgressStatsIdle(t *testing.T) {
+	delay := 20 * time.Millisecond
+	progress := streamy.Progress{}
+	if err := progress.EnableStats(streamy.ProgressStatsModeSimple); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	io.Copy(&progress, bytes.NewBufferString("foo"))
+	time.Sleep(delay)
+	if idle := progress.Stats().Idle; idle < delay {
+		t.Errorf("got %v, want >=%v", idle, delay)
+	}
+}
+
+func BenchmarkWatchdog(b *testing.B) {
+	wd := streamy.NewWatchdog(time.Second)
+	wd.Start(context.Background())
+	defer wd.Stop()
+	w := wd.Writer(io.Discard)
+	for i := 0; i < b.N; i++ {
+		w.Write([]byte("foo"))
+	}
+}